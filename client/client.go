@@ -42,6 +42,12 @@ func fetchData(payload map[string]interface{}, apiKey string) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
+// FetchData wraps fetchData for callers that handle errors themselves,
+// such as concurrent workers
+func FetchData(payload map[string]interface{}) ([]byte, error) {
+	return fetchData(payload, validateAPIKey())
+}
+
 // FetchDataOrExit wraps fetchData
 func FetchDataOrExit(payload map[string]interface{}) []byte {
 	apiKey := validateAPIKey()
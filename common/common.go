@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -221,8 +222,9 @@ func FormatPrice(n int64, useNum ...bool) string {
 	return strconv.FormatInt(n, 10)
 }
 
-// resolveFilePath resolves a filename to an absolute path
-func resolveFilePath(filename string) (string, error) {
+// ResolveFilePath resolves a relative filename against the directory of the
+// executable, where cached data files are kept.
+func ResolveFilePath(filename string) (string, error) {
 	if filepath.IsAbs(filename) {
 		return filename, nil
 	}
@@ -244,7 +246,7 @@ func LoadItemData(filename string, duration int64, cache ...bool) ([]Item, error
 		shouldCache = cache[0]
 	}
 
-	jsonPath, err := resolveFilePath(filename)
+	jsonPath, err := ResolveFilePath(filename)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "could not resolve file path: %v\n", err)
 		return nil, err
@@ -310,7 +312,7 @@ func fetchAndCache(jsonPath string, shouldCache bool) ([]byte, error) {
 
 // loadJSONFile reads a JSON file and returns its contents
 func loadJSONFile(filename string) ([]byte, error) {
-	jsonPath, err := resolveFilePath(filename)
+	jsonPath, err := ResolveFilePath(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -377,45 +379,13 @@ func TimeUntilISO8601(iso string) string {
 	if err != nil {
 		return "-" // or handle parse error as you prefer
 	}
-	now := time.Now().UTC()
-	diff := t.Sub(now)
+	diff := t.Sub(time.Now().UTC())
 
 	// if zero or negative, we’re done
 	if diff <= 0 {
 		return "0"
 	}
-
-	// break diff down into components
-	totalSeconds := int64(diff.Seconds())
-	weeks := totalSeconds / (7 * 24 * 3600)
-	totalSeconds %= 7 * 24 * 3600
-	days := totalSeconds / (24 * 3600)
-	totalSeconds %= 24 * 3600
-	hours := totalSeconds / 3600
-	totalSeconds %= 3600
-	minutes := totalSeconds / 60
-	seconds := totalSeconds % 60
-
-	// build the human‑readable parts
-	var parts []string
-	if weeks > 0 {
-		parts = append(parts, fmt.Sprintf("%dw", weeks))
-	}
-	if days > 0 {
-		parts = append(parts, fmt.Sprintf("%dd", days))
-	}
-	if hours > 0 {
-		parts = append(parts, fmt.Sprintf("%dh", hours))
-	}
-	if minutes > 0 {
-		parts = append(parts, fmt.Sprintf("%dm", minutes))
-	}
-	// always show seconds if it’s the only unit, otherwise only if >0
-	if seconds > 0 || len(parts) == 0 {
-		parts = append(parts, fmt.Sprintf("%ds", seconds))
-	}
-
-	return strings.Join(parts, " ")
+	return FormatDuration(diff)
 }
 
 // ElapsedSinceISO8601 takes an RFC3339 timestamp (EpochToISO8601),
@@ -427,16 +397,25 @@ func ElapsedSinceISO8601(iso string) string {
 	if err != nil {
 		return "0" // or handle error otherwise
 	}
-	now := time.Now().UTC()
-	diff := now.Sub(t)
+	diff := time.Now().UTC().Sub(t)
 
 	// if zero or negative (i.e. t is in the future), we’re done
 	if diff <= 0 {
 		return "0"
 	}
+	return FormatDuration(diff)
+}
+
+// FormatDuration renders d as weeks, days, hours, minutes and seconds,
+// e.g. "1w 2d 3h 4m 5s". Zero units are skipped; negative durations are
+// rendered as their absolute value.
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
 
-	// break diff down into components
-	totalSeconds := int64(diff.Seconds())
+	// break d down into components
+	totalSeconds := int64(d.Seconds())
 	weeks := totalSeconds / (7 * 24 * 3600)
 	totalSeconds %= 7 * 24 * 3600
 	days := totalSeconds / (24 * 3600)
@@ -468,6 +447,61 @@ func ElapsedSinceISO8601(iso string) string {
 	return strings.Join(parts, " ")
 }
 
+// EpochToTime converts milliseconds since the Unix epoch to a UTC time.
+func EpochToTime(ms int64) time.Time {
+	return time.UnixMilli(ms).UTC()
+}
+
+// FindItem looks up an item by numeric ID or by name/idName (case-insensitive).
+func FindItem(items []Item, arg string) (Item, error) {
+	// Try parsing as integer ID
+	if id, err := strconv.Atoi(arg); err == nil {
+		for _, it := range items {
+			if it.ID == id {
+				return it, nil
+			}
+		}
+	}
+	// Otherwise, match by name or idName (case-insensitive)
+	lower := strings.ToLower(arg)
+	for _, it := range items {
+		if strings.ToLower(it.Name) == lower || strings.ToLower(it.IDName) == lower {
+			return it, nil
+		}
+	}
+	return Item{}, fmt.Errorf("item %q not found", arg)
+}
+
+// ParseDuration extends time.ParseDuration with day ("d") and week ("w")
+// units, e.g. "7d", "2w" or "1d12h".
+func ParseDuration(s string) (time.Duration, error) {
+	var total time.Duration
+	rest := s
+	for _, unit := range []struct {
+		suffix string
+		size   time.Duration
+	}{{"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour}} {
+		i := strings.Index(rest, unit.suffix)
+		if i < 0 {
+			continue
+		}
+		n, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		total += time.Duration(n * float64(unit.size))
+		rest = rest[i+1:]
+	}
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		total += d
+	}
+	return total, nil
+}
+
 // LookUpItemName finds the item name by ID in the provided items slice.
 func LookUpItemName(id int, items []Item) string {
 	for _, item := range items {
@@ -512,6 +546,17 @@ func GetPetCategory(name string) string {
 	return ""
 }
 
+// GetPetData returns the icon and category of the pet species with the given
+// name (case-insensitive), and whether it is known.
+func GetPetData(name string) (PetData, bool) {
+	for _, pet := range AllPetTypes {
+		if strings.EqualFold(pet.Name, name) {
+			return pet, true
+		}
+	}
+	return PetData{}, false
+}
+
 // GetPetsByCategory returns a slice of all pets in the given category (case-insensitive).
 // If no pets are found, it returns an empty slice.
 func GetPetsByCategory(category string) []PetData {
@@ -593,3 +638,30 @@ func GetBoostsByTier(tier int) []ItemBoost {
 	}
 	return results
 }
+
+// BoostEffect is the structured form of a boost effect string such as
+// "2× Fish (15m)".
+type BoostEffect struct {
+	Multiplier float64
+	Action     string // e.g. "Fish" or "Pet Adventure Speed"
+	Duration   time.Duration
+}
+
+var boostEffectRe = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9]+)?)\s*[×xX]\s*(.+?)\s*\(([^)]+)\)\s*$`)
+
+// ParseBoostEffect parses an effect string of the form "<mult>× <action> (<duration>)".
+func ParseBoostEffect(effect string) (BoostEffect, error) {
+	m := boostEffectRe.FindStringSubmatch(effect)
+	if m == nil {
+		return BoostEffect{}, fmt.Errorf("unrecognised boost effect %q", effect)
+	}
+	mult, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return BoostEffect{}, fmt.Errorf("boost effect %q: %w", effect, err)
+	}
+	d, err := ParseDuration(m[3])
+	if err != nil {
+		return BoostEffect{}, fmt.Errorf("boost effect %q: %w", effect, err)
+	}
+	return BoostEffect{Multiplier: mult, Action: m[2], Duration: d}, nil
+}
@@ -3,11 +3,19 @@ package egg
 import (
 	"bcncli/client"
 	"bcncli/common"
+	"bcncli/pet"
 	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 )
 
+// Egg represents an unhatched egg. It lives in the pet package, which
+// also decodes eggs found among offspring.
+type Egg = pet.Egg
+
 var Cmd = &cobra.Command{
 	Use:   "egg",
 	Short: "Manage eggs",
@@ -15,17 +23,64 @@ var Cmd = &cobra.Command{
 
 func init() {
 	Cmd.AddCommand(infoCmd, ownedCmd, offspringCmd)
+
+	// Define flags for info command
+	infoCmd.Flags().Bool("debug", false, "Enable debug (JSON) output")
+
+	// Define flags for owned command
+	ownedCmd.Flags().Bool("debug", false, "Enable debug (JSON) output")
+	ownedCmd.Flags().String("sort", "", "Sort by fields, e.g. hatch,tier:desc")
+	ownedCmd.Flags().StringArrayP("where", "w", nil, "Filter expression, e.g. 'tier>=3 && species=Dolphin' (repeatable)")
+	ownedCmd.Flags().String("columns", defaultEggColumns, "Comma-separated columns to show")
+	ownedCmd.Flags().String("group", "", "Group table by these fields, e.g. species or species,tier")
+}
+
+// defaultEggColumns are the columns of egg owned without --columns.
+const defaultEggColumns = "id,species,parenta,parentb,tier,hatch,hatchesin"
+
+// eggFields are the fields of an egg table.
+var eggFields = pet.Fields[Egg]{
+	{Name: "id", Header: "ID", Value: func(e Egg) any { return e.ID }},
+	{Name: "species", Header: "Species", Value: func(e Egg) any { return e.Species }},
+	{Name: "category", Header: "Category", Value: func(e Egg) any { return common.GetPetCategory(e.Species) }},
+	{Name: "tier", Header: "Tier", Value: func(e Egg) any { return int64(e.Tier) }},
+	{Name: "gen", Header: "Gen", Value: func(e Egg) any { return int64(e.Generation) }},
+	{Name: "parenta", Header: "Parent A", Value: func(e Egg) any { return e.ParentAID }},
+	{Name: "parentb", Header: "Parent B", Value: func(e Egg) any { return e.ParentBID }},
+	{Name: "hatch", Header: "Hatch Time", Value: func(e Egg) any { return e.HatchDate }},
+	{Name: "hatchesin", Header: "Hatches In", Value: func(e Egg) any { return e.HatchDate },
+		Format: func(e Egg) string { return e.HatchesIn() }},
+	{Name: "skin", Header: "Skin", Value: func(e Egg) any { return e.Skin }},
+	{Name: "aura", Header: "Aura", Value: func(e Egg) any { return e.Aura }},
 }
 
 var infoCmd = &cobra.Command{
 	Use:   "info [id]",
-	Short: "Fetch egg info",
+	Short: "Show an egg's details",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		id := common.ParseID(args[0])
 		payload := map[string]interface{}{"type": "egg", "id": id}
-		data := client.FetchDataOrExit(payload)
-		common.PrintJSON(data)
+		raw := client.FetchDataOrExit(payload)
+
+		if debug, _ := cmd.Flags().GetBool("debug"); debug {
+			common.PrintJSON(raw)
+			return
+		}
+
+		var e Egg
+		if err := json.Unmarshal(raw, &e); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing egg data: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Egg (%d)\n\n", e.ID)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range eggFields[1:] {
+			fmt.Fprintf(w, "%s:\t%s\n", f.Header, f.Cell(e))
+		}
+		fmt.Fprintf(w, "Owner:\t%d\n", e.OwnerBCID)
+		w.Flush()
 	},
 }
 
@@ -37,20 +92,64 @@ var ownedCmd = &cobra.Command{
 		userId := common.ParseID(args[0])
 		payload := map[string]interface{}{"type": "userPetsAndEggs", "id": userId}
 		raw := client.FetchDataOrExit(payload)
+
+		// Parse wrapper
 		var resp map[string]json.RawMessage
-		json.Unmarshal(raw, &resp)
-		common.PrintJSON(resp["eggs"])
-	},
-}
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing response wrapper: %v\n", err)
+			os.Exit(1)
+		}
 
-var offspringCmd = &cobra.Command{
-	Use:   "offspring [id]",
-	Short: "Fetch offspring for an egg",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id := common.ParseID(args[0])
-		payload := map[string]interface{}{"type": "petOffspring", "id": id}
-		data := client.FetchDataOrExit(payload)
-		common.PrintJSON(data)
+		// Debug JSON
+		if debug, _ := cmd.Flags().GetBool("debug"); debug {
+			common.PrintJSON(resp["eggs"])
+			return
+		}
+
+		// Unmarshal eggs
+		var eggs []Egg
+		if err := json.Unmarshal(resp["eggs"], &eggs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing eggs data: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Filter, sort and pick columns
+		wheres, _ := cmd.Flags().GetStringArray("where")
+		eggs, err := pet.FilterRows(eggs, eggFields, wheres)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --where: %v\n", err)
+			os.Exit(1)
+		}
+		if sortSpec, _ := cmd.Flags().GetString("sort"); sortSpec != "" {
+			if err := pet.SortRows(eggs, eggFields, sortSpec); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --sort: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		columnSpec, _ := cmd.Flags().GetString("columns")
+		cols, err := eggFields.Select(columnSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --columns: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Group
+		groupSpec, _ := cmd.Flags().GetString("group")
+		if groupSpec == "" {
+			pet.PrintRows(os.Stdout, eggs, cols)
+			return
+		}
+		groups, err := pet.GroupRows(eggs, eggFields, groupSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --group: %v\n", err)
+			os.Exit(1)
+		}
+		for _, g := range groups {
+			fmt.Printf("%s (%d)\n", g.Key, len(g.Rows))
+			pet.PrintRows(os.Stdout, g.Rows, cols)
+			fmt.Println()
+		}
 	},
 }
+
+var offspringCmd = pet.NewOffspringCmd("Fetch offspring for an egg")
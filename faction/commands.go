@@ -1,6 +1,7 @@
 package faction
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -19,6 +20,24 @@ func init() {
 	Cmd.AddCommand(infoCmd, membersCmd, recruitingCmd, requestsCmd)
 }
 
+// Member represents a single entry of the factionMembers API response.
+type Member struct {
+	BcID int64  `json:"bcId"`
+	Name string `json:"name"`
+}
+
+// FetchMembers returns the members of a faction, exiting on error.
+func FetchMembers(factionID int) []Member {
+	payload := map[string]interface{}{"type": "factionMembers", "id": factionID}
+	raw := client.FetchDataOrExit(payload)
+	var members []Member
+	if err := json.Unmarshal(raw, &members); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing faction members: %v\n", err)
+		os.Exit(1)
+	}
+	return members
+}
+
 var infoCmd = &cobra.Command{
 	Use:   "info [id]",
 	Short: "Fetch faction info",
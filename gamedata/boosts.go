@@ -0,0 +1,225 @@
+package gamedata
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+	"bcncli/market"
+	"bcncli/profile"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	boostsCmd.Flags().String("action", "", "only show boosts for this action (fish, hunt, explore, mine, pet)")
+	boostsCmd.Flags().Int("id", 0, "bcId whose upgrades and cooldowns are used for the yield estimate")
+	boostsCmd.Flags().Duration("cooldown", 5*time.Minute, "cooldown between two uses of an action")
+	boostsCmd.Flags().Int64("yield", 0, "BC earned per action without boost (0 = average market value of the action's loot)")
+	boostsCmd.Flags().Float64("upgrade-bonus", 10, "yield added per action upgrade level, in percent")
+	Cmd.AddCommand(boostsCmd)
+}
+
+// boostRow is a boost item priced at the market with its estimated yield.
+type boostRow struct {
+	name   string
+	tier   int // 0 for pet boosts
+	effect common.BoostEffect
+	price  int64
+	basis  string // "market" or "worth"
+	uses   int    // action uses while the boost is active
+	extra  int64  // extra BC earned thanks to the boost, 0 when unknown
+}
+
+// yieldPerBC returns the extra yield for every BC spent on the boost.
+func (r boostRow) yieldPerBC() float64 {
+	if r.price <= 0 {
+		return 0
+	}
+	return float64(r.extra) / float64(r.price)
+}
+
+var boostsCmd = &cobra.Command{
+	Use:   "boosts",
+	Short: "Price boost items and estimate their yield per BC",
+	Long: `List every action and pet boost item with its parsed multiplier and
+duration, priced at the market overview value (falling back to its listed
+worth), and estimate the extra BC it earns: the action's yield, raised by
+--upgrade-bonus per upgrade level, times the extra multiplier, for every use
+that fits into the boost's duration.
+
+With --id the player's action upgrades are used, and uses are counted from
+when each action is off cooldown. Pet boosts have no yield estimate.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		action, _ := cmd.Flags().GetString("action")
+		bcID, _ := cmd.Flags().GetInt("id")
+		cooldown, _ := cmd.Flags().GetDuration("cooldown")
+		yield, _ := cmd.Flags().GetInt64("yield")
+		bonus, _ := cmd.Flags().GetFloat64("upgrade-bonus")
+
+		if cooldown <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: --cooldown must be positive")
+			os.Exit(1)
+		}
+
+		items, err := common.LoadItemData("itemid.json", 3600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading item data: %v\n", err)
+			os.Exit(1)
+		}
+		overview := market.FetchOverview()
+
+		var player *profile.ProfileInfo
+		if bcID != 0 {
+			p := profile.Fetch(bcID)
+			player = &p
+			fmt.Printf("%s (%d)\n\n", p.Name, p.ID)
+		}
+
+		type candidate struct {
+			name   string
+			tier   int
+			worth  int64
+			effect string
+		}
+		var candidates []candidate
+		for _, b := range common.AllItemBoosts {
+			candidates = append(candidates, candidate{b.Name, b.Tier, int64(b.Worth), b.Effect})
+		}
+		for _, b := range common.AllPetBoostItems {
+			candidates = append(candidates, candidate{b.Name, 0, int64(b.Worth), b.Effect})
+		}
+
+		var rows []boostRow
+		baseYield := make(map[string]int64)
+		for _, c := range candidates {
+			effect, err := common.ParseBoostEffect(c.effect)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %s: %v\n", c.name, err)
+				continue
+			}
+			if action != "" && !strings.HasPrefix(strings.ToLower(effect.Action), strings.ToLower(action)) {
+				continue
+			}
+			r := boostRow{name: c.name, tier: c.tier, effect: effect, price: c.worth, basis: "worth"}
+			if it, err := common.FindItem(items, c.name); err == nil {
+				if v, ok := overview.Price(it.ID); ok && v > 0 {
+					r.price, r.basis = v, "market"
+				}
+			}
+
+			if c.tier > 0 {
+				y, ok := baseYield[effect.Action]
+				if !ok {
+					y = yield
+					if y == 0 {
+						y = lootValue(items, overview, effect.Action)
+					}
+					baseYield[effect.Action] = y
+				}
+				levels, last := 0, int64(0)
+				if player != nil {
+					levels, last = actionState(*player, effect.Action)
+				}
+				r.uses = usesDuring(effect.Duration, cooldown, last)
+				perUse := float64(y) * (1 + float64(levels)*bonus/100)
+				r.extra = int64((effect.Multiplier - 1) * perUse * float64(r.uses))
+			}
+			rows = append(rows, r)
+		}
+
+		sort.SliceStable(rows, func(i, j int) bool {
+			if rows[i].effect.Action != rows[j].effect.Action {
+				return rows[i].effect.Action < rows[j].effect.Action
+			}
+			return rows[i].tier < rows[j].tier
+		})
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BOOST\tTIER\tACTION\tEFFECT\tPRICE\tBASIS\tUSES\tEXTRA YIELD\tYIELD/BC")
+		best := make(map[string]boostRow)
+		for _, r := range rows {
+			tier, uses, extra, perBC := "-", "-", "-", "-"
+			if r.tier > 0 {
+				tier = fmt.Sprint(r.tier)
+				uses = fmt.Sprint(r.uses)
+				extra = common.FormatPrice(r.extra)
+				perBC = fmt.Sprintf("%.3f", r.yieldPerBC())
+				if b, ok := best[r.effect.Action]; !ok || r.yieldPerBC() > b.yieldPerBC() {
+					best[r.effect.Action] = r
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%g× for %s\t%s\t%s\t%s\t%s\t%s\n",
+				r.name, tier, r.effect.Action, r.effect.Multiplier, common.FormatDuration(r.effect.Duration),
+				common.FormatPrice(r.price), r.basis, uses, extra, perBC)
+		}
+		w.Flush()
+
+		if len(best) == 0 {
+			return
+		}
+		fmt.Println()
+		for _, a := range []string{"Fish", "Hunt", "Explore", "Mine"} {
+			b, ok := best[a]
+			if !ok {
+				continue
+			}
+			fmt.Printf("Best %s boost: %s (tier %d, %.3f BC per BC, yield %s per use)\n",
+				strings.ToLower(a), b.name, b.tier, b.yieldPerBC(), common.FormatPrice(baseYield[a]))
+		}
+	},
+}
+
+// lootValue estimates the BC earned per action as the average market value
+// of the items whose loot sources mention the action.
+func lootValue(items []common.Item, overview market.OverviewResponse, action string) int64 {
+	var sum, n int64
+	for _, it := range items {
+		for _, src := range it.LootSources {
+			if strings.Contains(strings.ToLower(src), strings.ToLower(action)) {
+				if v, ok := overview.Price(it.ID); ok && v > 0 {
+					sum += v
+					n++
+				}
+				break
+			}
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / n
+}
+
+// actionState returns the player's upgrade levels for an action and when
+// they last used it, in epoch milliseconds.
+func actionState(p profile.ProfileInfo, action string) (int, int64) {
+	switch strings.ToLower(action) {
+	case "fish":
+		return p.Upgrades.Fish + p.Upgrades.FishExtra, p.Cooldowns.Fish
+	case "hunt":
+		return p.Upgrades.Hunt + p.Upgrades.HuntExtra, p.Cooldowns.Hunt
+	case "explore":
+		return p.Upgrades.Explore + p.Upgrades.ExploreExtra, p.Cooldowns.Explore
+	case "mine":
+		return p.Upgrades.Mine + p.Upgrades.MineExtra, p.Cooldowns.Mine
+	}
+	return 0, 0
+}
+
+// usesDuring counts the action uses that fit into a boost activated now,
+// given the action cooldown and when it was last used.
+func usesDuring(d, cooldown time.Duration, lastMs int64) int {
+	var wait time.Duration
+	if lastMs > 0 {
+		wait = max(time.Until(common.EpochToTime(lastMs).Add(cooldown)), 0)
+	}
+	if wait >= d {
+		return 0
+	}
+	return int((d-wait)/cooldown) + 1
+}
@@ -4,7 +4,6 @@ package gamedata
 import (
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"text/tabwriter"
 
@@ -61,7 +60,7 @@ var itemCmd = &cobra.Command{
 		}
 
 		// Find the requested item
-		item, err := findItem(items, arg)
+		item, err := common.FindItem(items, arg)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -71,26 +70,6 @@ var itemCmd = &cobra.Command{
 	},
 }
 
-// findItem looks up an item by numeric ID or by name/idName
-func findItem(items []common.Item, arg string) (common.Item, error) {
-	// Try parsing as integer ID
-	if id, err := strconv.Atoi(arg); err == nil {
-		for _, it := range items {
-			if it.ID == id {
-				return it, nil
-			}
-		}
-	}
-	// Otherwise, match by name or idName (case-insensitive)
-	lower := strings.ToLower(arg)
-	for _, it := range items {
-		if strings.ToLower(it.Name) == lower || strings.ToLower(it.IDName) == lower {
-			return it, nil
-		}
-	}
-	return common.Item{}, fmt.Errorf("item %q not found", arg)
-}
-
 // sanitizeEmoji returns a displayable emoji or alias for the terminal
 func sanitizeEmoji(e string) string {
 	// Discord-style <:name:id> custom emoji; fall back to alias
@@ -16,6 +16,7 @@ import (
 	"bcncli/pet"
 	"bcncli/profile"
 	"bcncli/search"
+	"bcncli/stable"
 )
 
 func main() {
@@ -48,6 +49,7 @@ func main() {
 	rootCmd.AddCommand(logs.Cmd)
 	rootCmd.AddCommand(gamedata.Cmd)
 	rootCmd.AddCommand(search.Cmd)
+	rootCmd.AddCommand(stable.Cmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -0,0 +1,395 @@
+package market
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+)
+
+// alertRulesFile holds the alert rules, next to the executable.
+const alertRulesFile = "alerts.json"
+
+// alertStateFile remembers which rules have fired, next to the executable.
+const alertStateFile = "alert-state.json"
+
+// webhookClient posts alert webhooks; its timeout is set by alert run.
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// Alert rule sources.
+const (
+	sourcePreview = "preview" // marketPreview value
+	sourceListing = "listing" // cheapest marketListings price
+)
+
+func init() {
+	alertAddCmd.Flags().String("item", "", "item ID or name")
+	alertAddCmd.Flags().Int64("below", 0, "fire when the price drops below this value")
+	alertAddCmd.Flags().Int64("above", 0, "fire when the price rises above this value")
+	alertAddCmd.Flags().String("source", sourcePreview, "price to watch: preview or listing (cheapest listing)")
+	alertAddCmd.Flags().String("exec", "", "command to run when the alert fires (run with sh -c)")
+	alertAddCmd.Flags().String("webhook", "", "URL to POST a JSON payload to when the alert fires")
+	alertAddCmd.MarkFlagRequired("item")
+
+	alertRunCmd.Flags().Duration("interval", time.Minute, "time between polls")
+	alertRunCmd.Flags().Bool("once", false, "poll once and exit")
+	alertRunCmd.Flags().Duration("webhook-timeout", 10*time.Second, "maximum time to wait for a webhook")
+
+	alertCmd.AddCommand(alertAddCmd, alertListCmd, alertRemoveCmd, alertRunCmd)
+	Cmd.AddCommand(alertCmd)
+}
+
+// AlertRule is a price alert stored in the alerts file.
+type AlertRule struct {
+	ID      int    `json:"id"`
+	ItemID  int    `json:"itemId"`
+	Below   int64  `json:"below,omitempty"`
+	Above   int64  `json:"above,omitempty"`
+	Source  string `json:"source"`
+	Exec    string `json:"exec,omitempty"`
+	Webhook string `json:"webhook,omitempty"`
+}
+
+// matches reports whether price triggers the rule.
+func (r AlertRule) matches(price int64) bool {
+	return (r.Below > 0 && price < r.Below) || (r.Above > 0 && price > r.Above)
+}
+
+// condition describes the rule's thresholds, e.g. "< 900".
+func (r AlertRule) condition() string {
+	switch {
+	case r.Below > 0 && r.Above > 0:
+		return fmt.Sprintf("< %s or > %s", common.FormatPrice(r.Below), common.FormatPrice(r.Above))
+	case r.Below > 0:
+		return "< " + common.FormatPrice(r.Below)
+	}
+	return "> " + common.FormatPrice(r.Above)
+}
+
+// alertEvent is printed and sent to hooks when a rule fires.
+type alertEvent struct {
+	RuleID    int    `json:"ruleId"`
+	ItemID    int    `json:"itemId"`
+	Item      string `json:"item"`
+	Source    string `json:"source"`
+	Price     int64  `json:"price"`
+	Condition string `json:"condition"`
+	Time      string `json:"time"`
+}
+
+var alertCmd = &cobra.Command{
+	Use:   "alert",
+	Short: "Manage market price alerts",
+}
+
+var alertAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a price alert",
+	Run: func(cmd *cobra.Command, args []string) {
+		itemArg, _ := cmd.Flags().GetString("item")
+		below, _ := cmd.Flags().GetInt64("below")
+		above, _ := cmd.Flags().GetInt64("above")
+		source, _ := cmd.Flags().GetString("source")
+		execCmd, _ := cmd.Flags().GetString("exec")
+		webhook, _ := cmd.Flags().GetString("webhook")
+
+		if below <= 0 && above <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: --below or --above is required")
+			os.Exit(1)
+		}
+		if source != sourcePreview && source != sourceListing {
+			fmt.Fprintf(os.Stderr, "Error: invalid source %s, must be preview or listing\n", source)
+			os.Exit(1)
+		}
+		items, _ := loadItemNames()
+		item, err := common.FindItem(items, itemArg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		rules := loadAlertRules()
+		rule := AlertRule{ItemID: item.ID, Below: below, Above: above, Source: source, Exec: execCmd, Webhook: webhook}
+		for _, r := range rules {
+			rule.ID = max(rule.ID, r.ID)
+		}
+		rule.ID++
+		rules = append(rules, rule)
+		saveAlertRules(rules)
+		fmt.Printf("Added alert %d: %s %s %s\n", rule.ID, item.Name, rule.Source, rule.condition())
+	},
+}
+
+var alertListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List price alerts",
+	Run: func(cmd *cobra.Command, args []string) {
+		rules := loadAlertRules()
+		_, nameByID := loadItemNames()
+		state := loadAlertState()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tITEM\tSOURCE\tCONDITION\tFIRED\tHOOKS")
+		for _, r := range rules {
+			hooks := "stdout"
+			if r.Exec != "" {
+				hooks += ", exec"
+			}
+			if r.Webhook != "" {
+				hooks += ", webhook"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\n", r.ID, itemLabel(nameByID, r.ItemID), r.Source, r.condition(), state[r.ID], hooks)
+		}
+		w.Flush()
+	},
+}
+
+var alertRemoveCmd = &cobra.Command{
+	Use:   "remove [alertId]",
+	Short: "Remove a price alert",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := common.ParseID(args[0])
+		rules := loadAlertRules()
+		kept := rules[:0]
+		for _, r := range rules {
+			if r.ID != id {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == len(rules) {
+			fmt.Fprintf(os.Stderr, "Error: no alert with ID %d\n", id)
+			os.Exit(1)
+		}
+		saveAlertRules(kept)
+
+		state := loadAlertState()
+		delete(state, id)
+		saveAlertState(state)
+		fmt.Printf("Removed alert %d\n", id)
+	},
+}
+
+var alertRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Poll the market and fire matching alerts",
+	Long: `Poll the market overview and, for listing rules, the listings of the
+watched items. A rule fires once when its condition starts to match and is
+re-armed when the condition clears, so it does not fire on every poll.
+
+A failed fetch is logged and retried on the next poll; rules whose price
+could not be fetched keep their state. With --once it is an error.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+		webhookClient.Timeout, _ = cmd.Flags().GetDuration("webhook-timeout")
+
+		rules := loadAlertRules()
+		if len(rules) == 0 {
+			fmt.Fprintln(os.Stderr, "no alerts configured, add one with 'market alert add'")
+			os.Exit(1)
+		}
+		_, nameByID := loadItemNames()
+		state := loadAlertState()
+
+		for {
+			err := pollAlerts(rules, state, nameByID)
+			saveAlertState(state)
+			if err != nil && once {
+				fmt.Fprintf(os.Stderr, "poll failed: %v\n", err)
+				os.Exit(1)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: poll failed, retrying in %s: %v\n",
+					time.Now().Format(time.RFC3339), interval, err)
+			}
+			if once {
+				return
+			}
+			time.Sleep(interval)
+		}
+	},
+}
+
+// pollAlerts evaluates every rule once and fires the ones that start
+// matching. Rules whose price could not be fetched are left as they are; the
+// first fetch error is returned after the others have been evaluated.
+func pollAlerts(rules []AlertRule, state map[int]bool, nameByID map[int]string) error {
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	overview, overviewErr := fetchOverview()
+	if overviewErr != nil {
+		fail(fmt.Errorf("overview: %w", overviewErr))
+	}
+	cheapest := make(map[int]int64)
+	failed := make(map[int]bool)
+	for _, r := range rules {
+		if _, done := cheapest[r.ItemID]; r.Source != sourceListing || done || failed[r.ItemID] {
+			continue
+		}
+		var listings []Listing
+		if err := fetchInto(map[string]interface{}{"type": "marketListings", "itemId": r.ItemID}, &listings); err != nil {
+			fail(fmt.Errorf("listings for item %d: %w", r.ItemID, err))
+			failed[r.ItemID] = true
+			continue
+		}
+		if book := newOrderBook(listings); len(book) > 0 {
+			cheapest[r.ItemID] = book[0].Price
+		} else {
+			cheapest[r.ItemID] = 0
+		}
+	}
+
+	for _, r := range rules {
+		var price int64
+		var ok bool
+		if r.Source == sourceListing {
+			if failed[r.ItemID] {
+				continue
+			}
+			price = cheapest[r.ItemID]
+			ok = price > 0
+		} else {
+			if overviewErr != nil {
+				continue
+			}
+			price, ok = overview.Price(r.ItemID)
+		}
+
+		if !ok || !r.matches(price) {
+			state[r.ID] = false // re-arm
+			continue
+		}
+		if state[r.ID] {
+			continue // already fired
+		}
+		state[r.ID] = true
+		fireAlert(r, alertEvent{
+			RuleID:    r.ID,
+			ItemID:    r.ItemID,
+			Item:      nameByID[r.ItemID],
+			Source:    r.Source,
+			Price:     price,
+			Condition: r.condition(),
+			Time:      time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+	return firstErr
+}
+
+// fireAlert prints the event and runs the rule's hooks.
+func fireAlert(r AlertRule, ev alertEvent) {
+	fmt.Printf("%s ALERT %d: %s (%d) %s %s (%s)\n",
+		ev.Time, ev.RuleID, ev.Item, ev.ItemID,
+		ev.Source, common.FormatPrice(ev.Price), ev.Condition)
+
+	if r.Exec != "" {
+		c := exec.Command("sh", "-c", r.Exec)
+		c.Env = append(os.Environ(),
+			"BCN_ALERT_ID="+strconv.Itoa(ev.RuleID),
+			"BCN_ALERT_ITEM_ID="+strconv.Itoa(ev.ItemID),
+			"BCN_ALERT_ITEM="+ev.Item,
+			"BCN_ALERT_SOURCE="+ev.Source,
+			"BCN_ALERT_PRICE="+strconv.FormatInt(ev.Price, 10),
+		)
+		c.Stdout, c.Stderr = os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "alert %d: exec hook failed: %v\n", ev.RuleID, err)
+		}
+	}
+
+	if r.Webhook != "" {
+		body, _ := json.Marshal(ev)
+		resp, err := webhookClient.Post(r.Webhook, "application/json", bytes.NewReader(body))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "alert %d: webhook failed: %v\n", ev.RuleID, err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			fmt.Fprintf(os.Stderr, "alert %d: webhook returned status %s\n", ev.RuleID, resp.Status)
+		}
+	}
+}
+
+// loadAlertRules returns the alert rules, sorted by ID. A missing file has
+// no rules.
+func loadAlertRules() []AlertRule {
+	var rules []AlertRule
+	path, err := common.ResolveFilePath(alertRulesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not resolve file path: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// saveAlertRules writes the alert rules next to the executable, leaving the
+// config file untouched.
+func saveAlertRules(rules []AlertRule) {
+	path, err := common.ResolveFilePath(alertRulesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not resolve file path: %v\n", err)
+		os.Exit(1)
+	}
+	data, _ := json.MarshalIndent(rules, "", "  ")
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write %s: %v\n", path, err)
+		os.Exit(1)
+	}
+}
+
+// loadAlertState returns which rules have fired and not yet re-armed.
+func loadAlertState() map[int]bool {
+	state := make(map[int]bool)
+	path, err := common.ResolveFilePath(alertStateFile)
+	if err != nil {
+		return state
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+// saveAlertState writes the fired state next to the executable.
+func saveAlertState(state map[int]bool) {
+	path, err := common.ResolveFilePath(alertStateFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not resolve file path: %v\n", err)
+		return
+	}
+	data, _ := json.Marshal(state)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not save alert state to %s: %v\n", path, err)
+	}
+}
@@ -0,0 +1,135 @@
+package market
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"bcncli/profile"
+)
+
+// orderBook holds the listings of one item, cheapest first.
+type orderBook []Listing
+
+// newOrderBook copies listings and sorts them by price, then by listing ID.
+func newOrderBook(listings []Listing) orderBook {
+	book := append(orderBook(nil), listings...)
+	sort.SliceStable(book, func(i, j int) bool {
+		if book[i].Price != book[j].Price {
+			return book[i].Price < book[j].Price
+		}
+		return book[i].ID < book[j].ID
+	})
+	return book
+}
+
+// volume returns the total number of units listed.
+func (b orderBook) volume() int64 {
+	var n int64
+	for _, l := range b {
+		n += l.Amount
+	}
+	return n
+}
+
+// vwap returns the volume-weighted average price of the book.
+func (b orderBook) vwap() float64 {
+	var units, value float64
+	for _, l := range b {
+		units += float64(l.Amount)
+		value += float64(l.Amount) * float64(l.Price)
+	}
+	if units == 0 {
+		return 0
+	}
+	return value / units
+}
+
+// fill walks the book buying up to n units from the cheapest listings.
+// It returns the units bought, their total cost and the highest price paid.
+func (b orderBook) fill(n int64) (filled, cost, worst int64) {
+	for _, l := range b {
+		if filled >= n {
+			break
+		}
+		take := min(l.Amount, n-filled)
+		filled += take
+		cost += take * l.Price
+		worst = l.Price
+	}
+	return filled, cost, worst
+}
+
+// sellerNames resolves and memoizes player names by bcId.
+type sellerNames map[int64]string
+
+// resolve looks up the names of the sellers in ids, in order, using a pool
+// of workers that together make at most rate profile requests per second.
+// Only the first limit distinct sellers are looked up (0 = all); the rest,
+// like sellers whose profile cannot be fetched, are shown by their bcId.
+func (s sellerNames) resolve(ids []int64, limit, workers int, rate float64) {
+	var todo []int64
+	for _, id := range ids {
+		if _, ok := s[id]; ok {
+			continue
+		}
+		s[id] = ""
+		if limit <= 0 || len(todo) < limit {
+			todo = append(todo, id)
+		}
+	}
+	if len(todo) == 0 {
+		return
+	}
+
+	workers = max(workers, 1)
+	var tick <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	jobs := make(chan int64)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				if tick != nil {
+					<-tick
+				}
+				if p, err := profile.Lookup(int(id)); err == nil {
+					mu.Lock()
+					s[id] = p.Name
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, id := range todo {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// label returns "Name (bcId)" for a seller, or just the bcId when the
+// profile cannot be fetched. Sellers not passed to resolve are looked up on
+// first use.
+func (s sellerNames) label(bcID int64) string {
+	name, ok := s[bcID]
+	if !ok {
+		if p, err := profile.Lookup(int(bcID)); err == nil {
+			name = p.Name
+		}
+		s[bcID] = name
+	}
+	if name == "" {
+		return fmt.Sprint(bcID)
+	}
+	return fmt.Sprintf("%s (%d)", name, bcID)
+}
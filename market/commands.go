@@ -5,12 +5,15 @@ import (
 	"bcncli/client"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"time"
 
 	"bcncli/common"
 
@@ -32,6 +35,129 @@ type OverviewResponse struct {
 	Data        map[string]int64 `json:"data"`
 }
 
+// Price returns the preview value of an item, if the snapshot has one.
+func (o OverviewResponse) Price(itemID int) (int64, bool) {
+	v, ok := o.Data["item"+strconv.Itoa(itemID)]
+	return v, ok
+}
+
+// FetchOverview fetches the marketPreview snapshot, exiting on error.
+func FetchOverview() OverviewResponse {
+	raw := client.FetchDataOrExit(map[string]interface{}{"type": "marketPreview"})
+	var overview OverviewResponse
+	if err := json.Unmarshal(raw, &overview); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse overview: %v\n", err)
+		os.Exit(1)
+	}
+	return overview
+}
+
+// fetchOverview fetches the marketPreview snapshot, for polling loops that
+// retry on the next tick instead of exiting.
+func fetchOverview() (OverviewResponse, error) {
+	var overview OverviewResponse
+	err := fetchInto(map[string]interface{}{"type": "marketPreview"}, &overview)
+	return overview, err
+}
+
+// fetchInto fetches payload and decodes the response into v.
+func fetchInto(payload map[string]interface{}, v any) error {
+	raw, err := client.FetchData(payload)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", payload["type"], err)
+	}
+	return nil
+}
+
+// fetchListings fetches the market listings of an item, exiting on error.
+func fetchListings(itemID int) []Listing {
+	raw := client.FetchDataOrExit(map[string]interface{}{"type": "marketListings", "itemId": itemID})
+	var listings []Listing
+	if err := json.Unmarshal(raw, &listings); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse listings: %v\n", err)
+		os.Exit(1)
+	}
+	return listings
+}
+
+// fetchAllListings fetches the listings of many items using a pool of workers
+// that together make at most rate requests per second. Items whose request
+// fails are reported on stderr, left out of the result and returned with
+// their error in failed.
+func fetchAllListings(itemIDs []int, workers int, rate float64) (books map[int][]Listing, failed map[int]error) {
+	workers = max(workers, 1)
+	var tick <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	out := make(map[int][]Listing, len(itemIDs))
+	failed = make(map[int]error)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				if tick != nil {
+					<-tick
+				}
+				raw, err := client.FetchData(map[string]interface{}{"type": "marketListings", "itemId": id})
+				var listings []Listing
+				if err == nil {
+					err = json.Unmarshal(raw, &listings)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: listings for item %d: %v\n", id, err)
+					mu.Lock()
+					failed[id] = err
+					mu.Unlock()
+					continue
+				}
+				mu.Lock()
+				out[id] = listings
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, id := range itemIDs {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+	return out, failed
+}
+
+// loadItemNames loads item data and builds an ID to name lookup, exiting on error.
+func loadItemNames() ([]common.Item, map[int]string) {
+	items, err := common.LoadItemData("itemid.json", 3600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load items: %v\n", err)
+		os.Exit(1)
+	}
+	nameByID := make(map[int]string, len(items))
+	for _, it := range items {
+		nameByID[it.ID] = it.Name
+	}
+	return items, nameByID
+}
+
+// itemLabel returns "Name (id)" for an item, or UNKNOWN(id).
+func itemLabel(nameByID map[int]string, id int) string {
+	name := nameByID[id]
+	if name == "" {
+		return fmt.Sprintf("UNKNOWN(%d)", id)
+	}
+	return fmt.Sprintf("%s (%d)", name, id)
+}
+
 // Cmd is the root command for market operations
 var Cmd = &cobra.Command{
 	Use:   "market",
@@ -40,8 +166,21 @@ var Cmd = &cobra.Command{
 
 func init() {
 	overviewCmd.Flags().BoolP("debug", "d", false, "print raw JSON response")
-	overviewCmd.Flags().StringP("sort", "s", "id", "sort overview by: id, name, or price")
+	overviewCmd.Flags().StringP("sort", "s", "id", "sort overview by: id, name, price, or change")
+	overviewCmd.Flags().Bool("desc", false, "sort in descending order")
+	overviewCmd.Flags().IntP("limit", "l", 0, "maximum number of items to show (0 = all)")
+	overviewCmd.Flags().StringSlice("attribute", nil, "only show items with one of these attributes")
+	overviewCmd.Flags().StringSlice("source", nil, "only show items dropped by one of these loot sources")
+	overviewCmd.Flags().Int64("min", 0, "minimum value (0 = no minimum)")
+	overviewCmd.Flags().Int64("max", 0, "maximum value (0 = no maximum)")
+	overviewCmd.Flags().String("search", "", "only show items whose name contains this text")
+	overviewCmd.Flags().String("changed-since", "", "show the change since the snapshot recorded this long ago (e.g. 1d)")
+	overviewCmd.Flags().String("file", defaultHistoryFile, "snapshot file for --changed-since (JSON lines)")
 	itemCmd.Flags().BoolP("debug", "d", false, "print raw JSON response")
+	itemCmd.Flags().Int64P("buy", "b", 0, "show the cost of buying this many units from the cheapest listings")
+	itemCmd.Flags().Bool("ids", false, "show seller IDs without resolving their names")
+	itemCmd.Flags().Int("names", 10, "resolve the names of at most this many sellers, cheapest first (0 = all)")
+	itemCmd.Flags().Float64("rate", 5, "maximum profile requests per second for seller names (0 = unlimited)")
 	userCmd.Flags().BoolP("debug", "d", false, "print raw JSON response")
 	Cmd.AddCommand(overviewCmd, itemCmd, userCmd)
 }
@@ -49,8 +188,21 @@ func init() {
 var overviewCmd = &cobra.Command{
 	Use:   "overview",
 	Short: "Show market overview",
+	Long: `Show the market overview value of every traded item, optionally
+filtered by item attribute, loot source, price range or name.
+
+With --changed-since the values are compared with the last snapshot
+recorded by "market record" at least that long ago.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		sortField, _ := cmd.Flags().GetString("sort")
+		desc, _ := cmd.Flags().GetBool("desc")
+		limit, _ := cmd.Flags().GetInt("limit")
+		attrs, _ := cmd.Flags().GetStringSlice("attribute")
+		sources, _ := cmd.Flags().GetStringSlice("source")
+		minPrice, _ := cmd.Flags().GetInt64("min")
+		maxPrice, _ := cmd.Flags().GetInt64("max")
+		search, _ := cmd.Flags().GetString("search")
+		changedSince, _ := cmd.Flags().GetString("changed-since")
 
 		// 1) fetch raw JSON
 		payload := map[string]interface{}{"type": "marketPreview"}
@@ -69,25 +221,33 @@ var overviewCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// 4) load items and build name lookup
+		// 4) load items and build lookup
 		items, err := common.LoadItemData("itemid.json", 3600)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "could not load items: %v\n", err)
 			os.Exit(1)
 		}
 
-		nameByID := make(map[int]string, len(items))
+		byID := make(map[int]common.Item, len(items))
 		for _, it := range items {
-			nameByID[it.ID] = it.Name
+			byID[it.ID] = it
 		}
 
-		// 5) build rows slice
+		// 5) find the snapshot to compare against
+		var previous *snapshot
+		if changedSince != "" {
+			previous = previousSnapshot(cmd, changedSince)
+		}
+
+		// 6) build rows slice, applying the filters
 		type row struct {
-			ID    int
-			Name  string
-			Value int64
+			ID     int
+			Name   string
+			Value  int64
+			Change float64 // percent, NaN when unknown
 		}
 		var rows []row
+		search = strings.ToLower(search)
 		for key, val := range responce.Data {
 			if !strings.HasPrefix(key, "item") {
 				continue
@@ -96,94 +256,212 @@ var overviewCmd = &cobra.Command{
 			if err != nil {
 				continue
 			}
-			name := nameByID[idNum]
-			if name == "" {
+			it, known := byID[idNum]
+			name := it.Name
+			if !known {
 				name = fmt.Sprintf("UNKNOWN(%d)", idNum)
 			}
-			rows = append(rows, row{ID: idNum, Name: name, Value: val})
+			if (minPrice > 0 && val < minPrice) || (maxPrice > 0 && val > maxPrice) {
+				continue
+			}
+			if search != "" && !strings.Contains(strings.ToLower(name), search) {
+				continue
+			}
+			if !hasAttribute(it, attrs) || !hasLootSource(it, sources) {
+				continue
+			}
+			r := row{ID: idNum, Name: name, Value: val, Change: math.NaN()}
+			if previous != nil {
+				if old, ok := previous.Price(idNum); ok && old != 0 {
+					r.Change = float64(val-old) / float64(old) * 100
+				}
+			}
+			rows = append(rows, r)
 		}
 
-		// 6) sort according to --sort
+		// 7) sort according to --sort and --desc
+		var less func(a, b row) bool
 		switch strings.ToLower(sortField) {
 		case "id":
-			sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+			less = func(a, b row) bool { return a.ID < b.ID }
 		case "name":
-			sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+			less = func(a, b row) bool { return a.Name < b.Name }
 		case "price":
-			sort.Slice(rows, func(i, j int) bool { return rows[i].Value < rows[j].Value })
+			less = func(a, b row) bool { return a.Value < b.Value }
+		case "change":
+			if previous == nil {
+				fmt.Fprintln(os.Stderr, "sorting by change requires --changed-since")
+				os.Exit(1)
+			}
+			// unknown changes sort first, so they end up last with --desc
+			less = func(a, b row) bool { return math.IsNaN(a.Change) && !math.IsNaN(b.Change) || a.Change < b.Change }
 		default:
-			fmt.Fprintf(os.Stderr, "invalid sort option: %s (must be id, name, or price)\n", sortField)
+			fmt.Fprintf(os.Stderr, "invalid sort option: %s (must be id, name, price, or change)\n", sortField)
 			os.Exit(1)
 		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			if desc {
+				return less(rows[j], rows[i])
+			}
+			return less(rows[i], rows[j])
+		})
+		if limit > 0 && len(rows) > limit {
+			rows = rows[:limit]
+		}
 
-		// 7) render as table
+		// 8) render as table
 		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-		defer w.Flush()
-
-		fmt.Fprintln(w, "ITEM\tVALUE")
+		if previous == nil {
+			fmt.Fprintln(w, "ITEM\tVALUE")
+		} else {
+			fmt.Fprintln(w, "ITEM\tVALUE\tCHANGE")
+		}
 		for _, r := range rows {
-			fmt.Fprintf(w, "%s (%d)\t%s\n", r.Name, r.ID, common.FormatPrice(r.Value))
+			if previous == nil {
+				fmt.Fprintf(w, "%s (%d)\t%s\n", r.Name, r.ID, common.FormatPrice(r.Value))
+				continue
+			}
+			change := "-"
+			if !math.IsNaN(r.Change) {
+				change = fmt.Sprintf("%+.1f%%", r.Change)
+			}
+			fmt.Fprintf(w, "%s (%d)\t%s\t%s\n", r.Name, r.ID, common.FormatPrice(r.Value), change)
 		}
 		w.Flush()
+		if previous != nil {
+			fmt.Printf("\nChanges since %s\n", common.EpochToISO8601(previous.RecordedAt))
+		}
 	},
 }
 
+// previousSnapshot returns the last snapshot recorded at least ago before now,
+// falling back to the oldest one when none is that old.
+func previousSnapshot(cmd *cobra.Command, ago string) *snapshot {
+	d, err := common.ParseDuration(ago)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --changed-since: %v\n", err)
+		os.Exit(1)
+	}
+	path := historyPath(cmd)
+	snaps, err := readSnapshots(path, time.Time{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read history: %v\n", err)
+		os.Exit(1)
+	}
+	if len(snaps) == 0 {
+		fmt.Fprintf(os.Stderr, "no snapshots in %s, record some with \"market record\"\n", path)
+		os.Exit(1)
+	}
+	cutoff := time.Now().Add(-d)
+	prev := &snaps[0]
+	for i := range snaps {
+		if common.EpochToTime(snaps[i].RecordedAt).After(cutoff) {
+			break
+		}
+		prev = &snaps[i]
+	}
+	if common.EpochToTime(prev.RecordedAt).After(cutoff) {
+		fmt.Fprintf(os.Stderr, "no snapshot older than %s, comparing with the oldest one\n", ago)
+	}
+	return prev
+}
+
+// hasLootSource reports whether the item drops from one of sources
+// (case-insensitive substring). An empty sources list matches every item.
+func hasLootSource(it common.Item, sources []string) bool {
+	if len(sources) == 0 {
+		return true
+	}
+	for _, want := range sources {
+		for _, src := range it.LootSources {
+			if strings.Contains(strings.ToLower(src), strings.ToLower(want)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 var itemCmd = &cobra.Command{
 	Use:   "item [itemId]",
-	Short: "List market listings for an item",
-	Args:  cobra.ExactArgs(1),
+	Short: "Show the order book for an item",
+	Long: `Show the listings of an item, cheapest first, with a summary of the book.
+
+Seller names cost one profile request each, so only the first --names
+sellers are resolved, at most --rate per second; the others are shown by
+their bcId.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		debug, _ := cmd.Flags().GetBool("debug")
+		buy, _ := cmd.Flags().GetInt64("buy")
+		idsOnly, _ := cmd.Flags().GetBool("ids")
+		names, _ := cmd.Flags().GetInt("names")
+		rate, _ := cmd.Flags().GetFloat64("rate")
 		itemID := common.ParseID(args[0])
 
-		// instead of a map, build a typed payload
-		type requestPayload struct {
-			Type   string `json:"type"`
-			ItemID int    `json:"itemId"`
-		}
-		payload := map[string]interface{}{"type": "marketListings", "itemId": itemID}
-		raw := client.FetchDataOrExit(payload)
-
 		if debug {
-			common.PrintJSON(raw)
+			payload := map[string]interface{}{"type": "marketListings", "itemId": itemID}
+			common.PrintJSON(client.FetchDataOrExit(payload))
 			return
 		}
 
-		// unmarshal into a slice of Listing structs
-		var listings []Listing
-		if err := json.Unmarshal(raw, &listings); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to parse listings: %v\n", err)
-			os.Exit(1)
+		book := newOrderBook(fetchListings(itemID))
+		_, nameByID := loadItemNames()
+		preview, hasPreview := FetchOverview().Price(itemID)
+		sellers := make(sellerNames)
+		if !idsOnly {
+			ids := make([]int64, 0, len(book))
+			for _, l := range book {
+				ids = append(ids, l.BcID)
+			}
+			sellers.resolve(ids, names, 1, rate)
 		}
 
-		// load item names as before
-		items, err := common.LoadItemData("itemid.json", 3600)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "could not load items: %v\n", err)
-			os.Exit(1)
+		fmt.Println(itemLabel(nameByID, itemID))
+		fmt.Println()
+
+		// pretty-print the book, cheapest first
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "PRICE\tAMOUNT\tCUMULATIVE\tSELLER\tLISTING")
+		var cumulative int64
+		for _, l := range book {
+			cumulative += l.Amount
+			seller := strconv.FormatInt(l.BcID, 10)
+			if !idsOnly {
+				seller = sellers.label(l.BcID)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%d\n",
+				common.FormatPrice(l.Price), l.Amount, cumulative, seller, l.ID)
 		}
+		w.Flush()
 
-		nameByID := make(map[int]string, len(items))
-		for _, it := range items {
-			nameByID[it.ID] = it.Name
+		if len(book) == 0 {
+			fmt.Println("no listings")
+			return
 		}
 
-		// pretty-print
-		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-		fmt.Fprintln(w, "ITEM\tPRICE\tAMOUNT")
-		for _, l := range listings {
-			name := nameByID[l.ItemID]
-			if name == "" {
-				name = fmt.Sprintf("UNKNOWN(%d)", l.ItemID)
+		// summary
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		best := book[0].Price
+		fmt.Fprintf(w, "Best price:\t%s\n", common.FormatPrice(best))
+		fmt.Fprintf(w, "VWAP:\t%s\n", common.FormatPrice(int64(book.vwap())))
+		fmt.Fprintf(w, "Volume:\t%d units in %d listings\n", book.volume(), len(book))
+		if hasPreview && preview > 0 {
+			spread := float64(best-preview) / float64(preview) * 100
+			fmt.Fprintf(w, "Preview value:\t%s (best is %+.1f%%)\n", common.FormatPrice(preview), spread)
+		}
+		if buy > 0 {
+			filled, cost, worst := book.fill(buy)
+			if filled == 0 {
+				fmt.Fprintf(w, "Buy %d:\tnothing to buy\n", buy)
+			} else {
+				fmt.Fprintf(w, "Buy %d:\t%s total, %s avg, up to %s each\n",
+					buy, common.FormatPrice(cost, true), common.FormatPrice(cost/filled), common.FormatPrice(worst))
+			}
+			if filled < buy {
+				fmt.Fprintf(w, "\tonly %d of %d units available\n", filled, buy)
 			}
-			fmt.Fprintf(
-				w,
-				"%s (%d)\t%s\t%d\n",
-				name,                        // item name
-				l.ItemID,                    // item ID
-				common.FormatPrice(l.Price), // price in your format
-				l.Amount,                    // quantity
-			)
 		}
 		w.Flush()
 	},
@@ -0,0 +1,193 @@
+package market
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	dealsCmd.Flags().Int64("budget", 0, "maximum total spend in BC (0 = unlimited)")
+	dealsCmd.Flags().StringSlice("attribute", nil, "only scan items with one of these attributes")
+	dealsCmd.Flags().String("against", "all", "reference price: preview, npc, recipe or all (best of the three)")
+	dealsCmd.Flags().Int64("min-profit", 1, "minimum expected profit per listing")
+	dealsCmd.Flags().IntP("limit", "l", 25, "maximum number of deals to show (0 = all)")
+	dealsCmd.Flags().Int("workers", 4, "concurrent listing requests")
+	dealsCmd.Flags().Float64("rate", 5, "maximum listing requests per second (0 = unlimited)")
+	Cmd.AddCommand(dealsCmd)
+}
+
+// deal is a listing priced below one of its reference values.
+type deal struct {
+	listing   Listing
+	units     int64 // units bought within the budget
+	reference int64 // per-unit value the listing is compared against
+	basis     string
+}
+
+// profit returns the expected profit of buying the deal's units.
+func (d deal) profit() int64 {
+	return (d.reference - d.listing.Price) * d.units
+}
+
+var dealsCmd = &cobra.Command{
+	Use:   "deals",
+	Short: "Find listings priced below their market, NPC or recipe value",
+	Long: `Scan the listings of every traded item and find ones priced below the
+market overview value, the NPC cost, or the summed market value of the
+item's recipe ingredients. Deals are ranked by expected profit and, with
+--budget, bought greedily until the budget is spent.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		budget, _ := cmd.Flags().GetInt64("budget")
+		attrs, _ := cmd.Flags().GetStringSlice("attribute")
+		against, _ := cmd.Flags().GetString("against")
+		minProfit, _ := cmd.Flags().GetInt64("min-profit")
+		limit, _ := cmd.Flags().GetInt("limit")
+		workers, _ := cmd.Flags().GetInt("workers")
+		rate, _ := cmd.Flags().GetFloat64("rate")
+
+		if !slices.Contains([]string{"preview", "npc", "recipe", "all"}, against) {
+			fmt.Fprintf(os.Stderr, "Error: invalid --against %s, must be preview, npc, recipe or all\n", against)
+			os.Exit(1)
+		}
+
+		items, nameByID := loadItemNames()
+		overview := FetchOverview()
+
+		// only scan traded items matching the attribute filter
+		byID := make(map[int]common.Item, len(items))
+		var ids []int
+		for _, it := range items {
+			byID[it.ID] = it
+			if _, traded := overview.Price(it.ID); traded && hasAttribute(it, attrs) {
+				ids = append(ids, it.ID)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "scanning %d items...\n", len(ids))
+		books, _ := fetchAllListings(ids, workers, rate)
+
+		var deals []deal
+		for id, listings := range books {
+			refs := referencePrices(byID[id], overview, byID)
+			for _, l := range listings {
+				basis, ref := bestReference(refs, against)
+				if l.Price <= 0 || ref-l.Price < 1 {
+					continue
+				}
+				d := deal{listing: l, units: l.Amount, reference: ref, basis: basis}
+				if d.profit() >= minProfit {
+					deals = append(deals, d)
+				}
+			}
+		}
+		sort.Slice(deals, func(i, j int) bool {
+			if deals[i].profit() != deals[j].profit() {
+				return deals[i].profit() > deals[j].profit()
+			}
+			return deals[i].listing.ID < deals[j].listing.ID
+		})
+
+		// spend the budget on the most profitable deals first
+		if budget > 0 {
+			remaining := budget
+			kept := deals[:0]
+			for _, d := range deals {
+				d.units = min(d.units, remaining/d.listing.Price)
+				if d.units == 0 || d.profit() < minProfit {
+					continue
+				}
+				remaining -= d.units * d.listing.Price
+				kept = append(kept, d)
+			}
+			deals = kept
+		}
+		if limit > 0 && len(deals) > limit {
+			deals = deals[:limit]
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ITEM\tPRICE\tUNITS\tREFERENCE\tBASIS\tPROFIT\tSELLER\tLISTING")
+		var spend, profit int64
+		for _, d := range deals {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%d\t%d\n",
+				itemLabel(nameByID, d.listing.ItemID),
+				common.FormatPrice(d.listing.Price), d.units,
+				common.FormatPrice(d.reference), d.basis,
+				common.FormatPrice(d.profit()), d.listing.BcID, d.listing.ID)
+			spend += d.units * d.listing.Price
+			profit += d.profit()
+		}
+		w.Flush()
+		fmt.Printf("\n%d deals, spend %s, expected profit %s\n",
+			len(deals), common.FormatPrice(spend), common.FormatPrice(profit))
+	},
+}
+
+// referencePrices returns the known per-unit values of an item by basis.
+func referencePrices(it common.Item, overview OverviewResponse, byID map[int]common.Item) map[string]int64 {
+	refs := make(map[string]int64)
+	if v, ok := overview.Price(it.ID); ok && v > 0 {
+		refs["preview"] = v
+	}
+	if it.Cost > 0 {
+		refs["npc"] = it.Cost
+	}
+	if len(it.Recipe) > 0 {
+		var sum int64
+		for _, ing := range it.Recipe {
+			v, ok := overview.Price(ing.ID)
+			if !ok {
+				if cost := byID[ing.ID].Cost; cost > 0 {
+					v, ok = cost, true
+				}
+			}
+			if !ok {
+				sum = 0
+				break
+			}
+			sum += v * int64(ing.Count)
+		}
+		if sum > 0 {
+			refs["recipe"] = sum
+		}
+	}
+	return refs
+}
+
+// bestReference picks the reference to compare against: the requested basis,
+// or the highest known value for "all".
+func bestReference(refs map[string]int64, against string) (string, int64) {
+	if against != "all" {
+		return against, refs[against]
+	}
+	basis, best := "", int64(0)
+	for _, b := range []string{"preview", "npc", "recipe"} {
+		if refs[b] > best {
+			basis, best = b, refs[b]
+		}
+	}
+	return basis, best
+}
+
+// hasAttribute reports whether the item has one of attrs (case-insensitive).
+// An empty attrs list matches every item.
+func hasAttribute(it common.Item, attrs []string) bool {
+	if len(attrs) == 0 {
+		return true
+	}
+	for _, want := range attrs {
+		for _, a := range it.Attributes {
+			if strings.EqualFold(a, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
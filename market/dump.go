@@ -0,0 +1,213 @@
+package market
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	dumpCmd.Flags().String("db", "market.sqlite", "SQLite database file")
+	dumpCmd.Flags().Int("workers", 4, "concurrent listing requests")
+	dumpCmd.Flags().Float64("rate", 5, "maximum listing requests per second (0 = unlimited)")
+	Cmd.AddCommand(dumpCmd)
+}
+
+// dumpSchema creates the dump tables. Every run adds a row to snapshots;
+// listings, preview and snapshot_errors rows reference it, so older
+// snapshots are kept. An item listed in snapshot_errors has no listings in
+// that snapshot because its request failed, not because nothing was listed.
+// items and recipes always hold the latest item data.
+const dumpSchema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	taken_at        INTEGER NOT NULL, -- epoch ms
+	preview_updated INTEGER           -- lastUpdated of marketPreview, epoch ms
+);
+CREATE TABLE IF NOT EXISTS listings (
+	snapshot_id INTEGER NOT NULL REFERENCES snapshots(id),
+	listing_id  INTEGER NOT NULL,
+	bc_id       INTEGER NOT NULL,
+	item_id     INTEGER NOT NULL,
+	price       INTEGER NOT NULL,
+	amount      INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS listings_snapshot_item ON listings(snapshot_id, item_id);
+CREATE TABLE IF NOT EXISTS snapshot_errors (
+	snapshot_id INTEGER NOT NULL REFERENCES snapshots(id),
+	item_id     INTEGER NOT NULL,
+	error       TEXT NOT NULL,
+	PRIMARY KEY (snapshot_id, item_id)
+);
+CREATE TABLE IF NOT EXISTS preview (
+	snapshot_id INTEGER NOT NULL REFERENCES snapshots(id),
+	item_id     INTEGER NOT NULL,
+	value       INTEGER NOT NULL,
+	PRIMARY KEY (snapshot_id, item_id)
+);
+CREATE TABLE IF NOT EXISTS items (
+	id           INTEGER PRIMARY KEY,
+	name         TEXT NOT NULL,
+	id_name      TEXT,
+	emoji        TEXT,
+	cost         INTEGER,
+	uncraftable  INTEGER,
+	use_limit    INTEGER,
+	attributes   TEXT, -- comma-separated
+	loot_sources TEXT, -- comma-separated
+	description  TEXT
+);
+CREATE TABLE IF NOT EXISTS recipes (
+	item_id       INTEGER NOT NULL,
+	ingredient_id INTEGER NOT NULL,
+	count         INTEGER NOT NULL,
+	PRIMARY KEY (item_id, ingredient_id)
+);
+`
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Append a market snapshot to a SQLite database",
+	Long: `Fetch the listings of every item and the market overview, and append
+them to a SQLite database as a new snapshot for ad-hoc SQL. The items and
+recipes tables are refreshed from the item data on every run.
+
+Items whose listings could not be fetched are recorded in snapshot_errors,
+so a missing item can be told apart from one with nothing listed. Exclude
+them from spread and supply queries with
+
+  AND l.item_id NOT IN (SELECT item_id FROM snapshot_errors WHERE snapshot_id = l.snapshot_id)
+
+Example: cheapest listing per item in the latest snapshot
+
+  SELECT i.name, MIN(l.price) FROM listings l JOIN items i ON i.id = l.item_id
+  WHERE l.snapshot_id = (SELECT MAX(id) FROM snapshots) GROUP BY l.item_id;`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbPath, _ := cmd.Flags().GetString("db")
+		workers, _ := cmd.Flags().GetInt("workers")
+		rate, _ := cmd.Flags().GetFloat64("rate")
+
+		items, _ := loadItemNames()
+		ids := make([]int, 0, len(items))
+		for _, it := range items {
+			ids = append(ids, it.ID)
+		}
+		overview := FetchOverview()
+		fmt.Fprintf(os.Stderr, "fetching listings for %d items...\n", len(ids))
+		books, failed := fetchAllListings(ids, workers, rate)
+
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not open database: %v\n", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		snapshotID, listings, err := writeDump(db, items, overview, books, failed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write dump: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("snapshot %d: %d listings, %d preview values, %d items written to %s\n",
+			snapshotID, listings, len(overview.Data), len(items), dbPath)
+		if len(failed) > 0 {
+			fmt.Printf("snapshot %d is incomplete: listings of %d item(s) could not be fetched, see snapshot_errors\n",
+				snapshotID, len(failed))
+		}
+	},
+}
+
+// writeDump stores one snapshot in a single transaction and returns its ID
+// and the number of listings written. Items in failed are recorded in
+// snapshot_errors.
+func writeDump(db *sql.DB, items []common.Item, overview OverviewResponse, books map[int][]Listing, failed map[int]error) (int64, int, error) {
+	if _, err := db.Exec(dumpSchema); err != nil {
+		return 0, 0, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO snapshots (taken_at, preview_updated) VALUES (?, ?)`,
+		time.Now().UnixMilli(), overview.LastUpdated)
+	if err != nil {
+		return 0, 0, err
+	}
+	snapshotID, err := res.LastInsertId()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// listings, in item order so rows are stable between runs
+	itemIDs := make([]int, 0, len(books))
+	for id := range books {
+		itemIDs = append(itemIDs, id)
+	}
+	sort.Ints(itemIDs)
+	stmt, err := tx.Prepare(`INSERT INTO listings (snapshot_id, listing_id, bc_id, item_id, price, amount) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer stmt.Close()
+	written := 0
+	for _, id := range itemIDs {
+		for _, l := range books[id] {
+			if _, err := stmt.Exec(snapshotID, l.ID, l.BcID, l.ItemID, l.Price, l.Amount); err != nil {
+				return 0, 0, err
+			}
+			written++
+		}
+	}
+
+	failedIDs := make([]int, 0, len(failed))
+	for id := range failed {
+		failedIDs = append(failedIDs, id)
+	}
+	sort.Ints(failedIDs)
+	for _, id := range failedIDs {
+		if _, err := tx.Exec(`INSERT INTO snapshot_errors (snapshot_id, item_id, error) VALUES (?, ?, ?)`,
+			snapshotID, id, failed[id].Error()); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	for _, it := range items {
+		if v, ok := overview.Price(it.ID); ok {
+			if _, err := tx.Exec(`INSERT INTO preview (snapshot_id, item_id, value) VALUES (?, ?, ?)`,
+				snapshotID, it.ID, v); err != nil {
+				return 0, 0, err
+			}
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO items
+			(id, name, id_name, emoji, cost, uncraftable, use_limit, attributes, loot_sources, description)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			it.ID, it.Name, it.IDName, it.Emoji, it.Cost, it.Uncraftable, it.UseLimit,
+			strings.Join(it.Attributes, ","), strings.Join(it.LootSources, ","), it.Description); err != nil {
+			return 0, 0, err
+		}
+		if _, err := tx.Exec(`DELETE FROM recipes WHERE item_id = ?`, it.ID); err != nil {
+			return 0, 0, err
+		}
+		for _, ing := range it.Recipe {
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO recipes (item_id, ingredient_id, count) VALUES (?, ?, ?)`,
+				it.ID, ing.ID, ing.Count); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return snapshotID, written, nil
+}
@@ -0,0 +1,275 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"bcncli/common"
+	"bcncli/profile"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	foodCmd.Flags().Int64P("energy", "e", 0, "energy to fill with the cheapest mix (default: full capacity when a bcId is given)")
+	foodCmd.Flags().Int64("base-capacity", 100000, "pet energy capacity without the RaisePetEnergyCapacity perk (assumed, not from game data)")
+	foodCmd.Flags().Float64("capacity-per-level", 10, "capacity added per RaisePetEnergyCapacity level, in percent of --base-capacity (assumed)")
+	foodCmd.Flags().Int("workers", 4, "concurrent listing requests")
+	foodCmd.Flags().Float64("rate", 5, "maximum listing requests per second (0 = unlimited)")
+	Cmd.AddCommand(foodCmd)
+}
+
+// foodPrice is a food item joined with its market value.
+type foodPrice struct {
+	common.FoodItem
+	itemID int
+	value  int64 // marketPreview value, 0 when not traded
+}
+
+// perBC returns the energy bought per BC at the preview value.
+func (f foodPrice) perBC() float64 {
+	if f.value <= 0 {
+		return 0
+	}
+	return float64(f.Energy) / float64(f.value)
+}
+
+// PetEnergyCapacity returns the energy capacity of a pet: base, raised by
+// perLevel percent per RaisePetEnergyCapacity perk level. The API does not
+// publish either number, so callers take them from flags.
+func PetEnergyCapacity(base int64, perLevel float64, level int) int64 {
+	return int64(float64(base) * (1 + float64(level)*perLevel/100))
+}
+
+// foodPurchase is the part of the mix bought from one listing.
+type foodPurchase struct {
+	food    foodPrice
+	listing Listing
+	units   int64
+}
+
+var foodCmd = &cobra.Command{
+	Use:   "food [bcId]",
+	Short: "Rank pet food by energy per BC and find the cheapest mix",
+	Long: `Join every food with its market overview value and rank them by energy
+per BC. With --energy, or a bcId, the cheapest mix of current listings that
+fills that much energy is computed. A bcId sets the target to the player's
+pet energy capacity, including the RaisePetEnergyCapacity perk.
+
+The API does not publish pet energy capacity. It is estimated as
+--base-capacity plus --capacity-per-level percent per perk level; both
+defaults are assumptions, so override them when the game's numbers are known.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target, _ := cmd.Flags().GetInt64("energy")
+		workers, _ := cmd.Flags().GetInt("workers")
+		rate, _ := cmd.Flags().GetFloat64("rate")
+
+		if len(args) == 1 && target == 0 {
+			p := profile.Fetch(common.ParseID(args[0]))
+			level := p.Perks.RaisePetEnergyCapacity
+			base, _ := cmd.Flags().GetInt64("base-capacity")
+			perLevel, _ := cmd.Flags().GetFloat64("capacity-per-level")
+			target = PetEnergyCapacity(base, perLevel, level)
+			fmt.Printf("%s (%d): pet energy capacity %d (RaisePetEnergyCapacity level %d)\n\n",
+				p.Name, p.ID, target, level)
+		}
+
+		items, _ := loadItemNames()
+		overview := FetchOverview()
+		var foods []foodPrice
+		for _, f := range common.AllFoodItems {
+			it, err := common.FindItem(items, f.Name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+				continue
+			}
+			v, _ := overview.Price(it.ID)
+			foods = append(foods, foodPrice{FoodItem: f, itemID: it.ID, value: v})
+		}
+		sort.SliceStable(foods, func(i, j int) bool { return foods[i].perBC() > foods[j].perBC() })
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "FOOD\tENERGY\tVALUE\tENERGY/BC")
+		for _, f := range foods {
+			if f.value <= 0 {
+				fmt.Fprintf(w, "%s\t%d\t-\t-\n", f.Name, f.Energy)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%.3f\n", f.Name, f.Energy, common.FormatPrice(f.value), f.perBC())
+		}
+		w.Flush()
+
+		if target <= 0 {
+			return
+		}
+
+		ids := make([]int, 0, len(foods))
+		for _, f := range foods {
+			ids = append(ids, f.itemID)
+		}
+		books, _ := fetchAllListings(ids, workers, rate)
+		mix, energy, cost := cheapestFoodMix(foods, books, target)
+
+		fmt.Printf("\nCheapest mix for %d energy\n\n", target)
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "FOOD\tUNITS\tPRICE\tENERGY\tCOST\tLISTING")
+		for _, m := range mix {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\t%d\n",
+				m.food.Name, m.units, common.FormatPrice(m.listing.Price),
+				m.units*int64(m.food.Energy), common.FormatPrice(m.units*m.listing.Price), m.listing.ID)
+		}
+		w.Flush()
+
+		fmt.Println()
+		if energy < target {
+			fmt.Printf("Only %d of %d energy is listed, for %s\n", energy, target, common.FormatPrice(cost, true))
+			return
+		}
+		fmt.Printf("Total: %d energy for %s (%.3f energy/BC)\n",
+			energy, common.FormatPrice(cost, true), float64(energy)/float64(max(cost, 1)))
+	},
+}
+
+// cheapestFoodMix finds the cheapest set of listing units that adds up to
+// at least target energy. It returns the purchases, the energy bought and
+// the total cost. When less than target is listed, everything is bought.
+//
+// This is a min-cost cover: every listing is split into chunks of 1, 2, 4...
+// units, and dp[e] holds the cheapest cost of e energy, with e counted in
+// multiples of the greatest common divisor of the food energies and capped
+// at target. The DP is exact, but its time and memory grow with the number
+// of energy states, so it covers at most maxFoodStates of them: for larger
+// targets the cheapest energy per BC is bought greedily first, and the DP
+// only fills the rest. Such a mix may cost slightly more than the optimum.
+func cheapestFoodMix(foods []foodPrice, books map[int][]Listing, target int64) ([]foodPurchase, int64, int64) {
+	var offers []foodPurchase
+	var listed int64
+	for _, f := range foods {
+		for _, l := range books[f.itemID] {
+			if l.Price > 0 && l.Amount > 0 && f.Energy > 0 {
+				offers = append(offers, foodPurchase{food: f, listing: l, units: l.Amount})
+				listed += l.Amount * int64(f.Energy)
+			}
+		}
+	}
+	costPerEnergy := func(o foodPurchase) float64 { return float64(o.listing.Price) / float64(o.food.Energy) }
+	sort.SliceStable(offers, func(i, j int) bool { return costPerEnergy(offers[i]) < costPerEnergy(offers[j]) })
+
+	if target <= 0 {
+		return nil, 0, 0
+	}
+	if listed < target {
+		var cost int64
+		for _, o := range offers {
+			cost += o.units * o.listing.Price
+		}
+		return offers, listed, cost
+	}
+
+	step := int64(0)
+	for _, o := range offers {
+		step = gcd(step, int64(o.food.Energy))
+	}
+
+	// buy the cheapest energy per BC until at most maxFoodStates steps are
+	// left for the DP
+	bought := make([]int64, len(offers))
+	remaining := target
+	if reserve := maxFoodStates * step; remaining > reserve {
+		for i, o := range offers {
+			if remaining <= reserve {
+				break
+			}
+			energy := int64(o.food.Energy)
+			bought[i] = min(o.units, (remaining-reserve+energy-1)/energy)
+			remaining -= bought[i] * energy
+		}
+	}
+	goal := max(remaining+step-1, 0) / step
+
+	type chunk struct {
+		offer         int
+		units, energy int64
+		cost          int64
+	}
+	var chunks []chunk
+	for i, o := range offers {
+		per := int64(o.food.Energy) / step
+		// more units than fill the goal on their own are never needed
+		left := min(o.units-bought[i], (goal+per-1)/per)
+		for size := int64(1); left > 0; size *= 2 {
+			n := min(size, left)
+			chunks = append(chunks, chunk{i, n, n * per, n * o.listing.Price})
+			left -= n
+		}
+	}
+
+	const unreachable = math.MaxInt64
+	dp := make([]int64, goal+1)
+	for e := range dp[1:] {
+		dp[e+1] = unreachable
+	}
+	// took[c] marks the states chunk c improved. Below the goal the previous
+	// state follows from the chunk's energy; at the goal it is kept in
+	// goalFrom[c], since any state within reach of the goal may lead to it.
+	took := make([][]uint64, len(chunks))
+	goalFrom := make([]int64, len(chunks))
+	for c, ch := range chunks {
+		took[c] = make([]uint64, goal/64+1)
+		for e := goal; e >= 0; e-- {
+			if dp[e] == unreachable {
+				continue
+			}
+			next := min(e+ch.energy, goal)
+			if cost := dp[e] + ch.cost; cost < dp[next] {
+				dp[next] = cost
+				took[c][next/64] |= 1 << (next % 64)
+				if next == goal {
+					goalFrom[c] = e
+				}
+			}
+		}
+	}
+
+	units := bought
+	for c, e := len(chunks)-1, goal; c >= 0; c-- {
+		if took[c][e/64]&(1<<(e%64)) == 0 {
+			continue
+		}
+		units[chunks[c].offer] += chunks[c].units
+		if e == goal {
+			e = goalFrom[c]
+		} else {
+			e -= chunks[c].energy
+		}
+	}
+
+	var mix []foodPurchase
+	var energy, cost int64
+	for i, o := range offers {
+		if units[i] == 0 {
+			continue
+		}
+		o.units = units[i]
+		mix = append(mix, o)
+		energy += o.units * int64(o.food.Energy)
+		cost += o.units * o.listing.Price
+	}
+	return mix, energy, cost
+}
+
+// maxFoodStates bounds the energy states of the cheapestFoodMix DP, and so
+// its time and memory.
+const maxFoodStates = 1 << 14
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
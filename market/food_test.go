@@ -0,0 +1,99 @@
+package market
+
+import (
+	"testing"
+
+	"bcncli/common"
+)
+
+func TestCheapestFoodMix(t *testing.T) {
+	small := foodPrice{FoodItem: common.FoodItem{Name: "Seaweed", Energy: 25}, itemID: 1}
+	large := foodPrice{FoodItem: common.FoodItem{Name: "Golden Wheat", Energy: 100}, itemID: 2}
+	foods := []foodPrice{small, large}
+
+	tests := []struct {
+		name       string
+		books      map[int][]Listing
+		target     int64
+		wantEnergy int64
+		wantCost   int64
+	}{
+		{
+			// greedy buys all three cheaper-per-energy units (75 for 60) and
+			// then a whole large unit (90), 150 in total
+			name: "greedy overshoots",
+			books: map[int][]Listing{
+				1: {{ID: 1, Price: 20, Amount: 3}},
+				2: {{ID: 2, Price: 90, Amount: 1}},
+			},
+			target:     100,
+			wantEnergy: 100,
+			wantCost:   90,
+		},
+		{
+			name: "trims the last listing",
+			books: map[int][]Listing{
+				1: {{ID: 1, Price: 20, Amount: 10}},
+			},
+			target:     60,
+			wantEnergy: 75,
+			wantCost:   60,
+		},
+		{
+			name: "mixes listings of one food",
+			books: map[int][]Listing{
+				1: {{ID: 1, Price: 30, Amount: 1}, {ID: 2, Price: 20, Amount: 2}},
+				2: {{ID: 3, Price: 200, Amount: 5}},
+			},
+			target:     75,
+			wantEnergy: 75,
+			wantCost:   70,
+		},
+		{
+			name: "not enough listed",
+			books: map[int][]Listing{
+				1: {{ID: 1, Price: 20, Amount: 1}},
+				2: {{ID: 2, Price: 90, Amount: 1}},
+			},
+			target:     500,
+			wantEnergy: 125,
+			wantCost:   110,
+		},
+		{
+			// far beyond maxFoodStates: bought greedily, then the DP fills
+			// the last part
+			name: "large target",
+			books: map[int][]Listing{
+				1: {{ID: 1, Price: 10, Amount: 1_000_000}},
+				2: {{ID: 2, Price: 50, Amount: 1_000_000}},
+			},
+			target:     10_000_010,
+			wantEnergy: 10_000_025,
+			wantCost:   4_000_010,
+		},
+		{
+			name:   "nothing listed",
+			books:  map[int][]Listing{},
+			target: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mix, energy, cost := cheapestFoodMix(foods, tt.books, tt.target)
+			if energy != tt.wantEnergy || cost != tt.wantCost {
+				t.Fatalf("got %d energy for %d, want %d for %d", energy, cost, tt.wantEnergy, tt.wantCost)
+			}
+			var units int64
+			for _, m := range mix {
+				if m.units > m.listing.Amount {
+					t.Errorf("listing %d: bought %d of %d units", m.listing.ID, m.units, m.listing.Amount)
+				}
+				units += m.units
+			}
+			if energy > 0 && units == 0 {
+				t.Errorf("energy %d bought without purchases", energy)
+			}
+		})
+	}
+}
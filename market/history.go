@@ -0,0 +1,288 @@
+package market
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultHistoryFile is the append-only snapshot file, kept next to the
+// executable like itemid.json.
+const defaultHistoryFile = "market-history.jsonl"
+
+func init() {
+	recordCmd.Flags().Duration("interval", 5*time.Minute, "time between snapshots")
+	recordCmd.Flags().Bool("once", false, "record a single snapshot and exit")
+	recordCmd.Flags().String("file", defaultHistoryFile, "snapshot file (JSON lines)")
+
+	historyCmd.Flags().String("since", "7d", "how far back to look (e.g. 12h, 7d, 2w)")
+	historyCmd.Flags().String("bucket", "1d", "bucket size (e.g. 1h, 1d)")
+	historyCmd.Flags().String("file", defaultHistoryFile, "snapshot file (JSON lines)")
+	historyCmd.Flags().Bool("csv", false, "print buckets as CSV")
+
+	Cmd.AddCommand(recordCmd, historyCmd)
+}
+
+// snapshot is one recorded marketPreview response.
+type snapshot struct {
+	RecordedAt int64 `json:"recordedAt"`
+	OverviewResponse
+}
+
+// historyBucket aggregates the preview values of one item over a time span.
+type historyBucket struct {
+	Start time.Time
+	Min   int64
+	Max   int64
+	Sum   int64
+	Close int64
+	Count int
+}
+
+// Avg returns the mean value in the bucket.
+func (b historyBucket) Avg() int64 {
+	if b.Count == 0 {
+		return 0
+	}
+	return b.Sum / int64(b.Count)
+}
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record market overview snapshots to a local file",
+	Long: `Poll the market overview and append every new snapshot to a local
+JSON lines file. Snapshots whose lastUpdated has not changed since the
+previous one are skipped. Use --once to record a single snapshot from cron.
+
+A failed fetch is logged and retried on the next tick; with --once it is an
+error.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+		path := historyPath(cmd)
+
+		var last int64
+		if snaps, err := readSnapshots(path, time.Time{}); err == nil && len(snaps) > 0 {
+			last = snaps[len(snaps)-1].LastUpdated
+		}
+
+		for {
+			overview, err := fetchOverview()
+			switch {
+			case err != nil && once:
+				fmt.Fprintf(os.Stderr, "could not fetch overview: %v\n", err)
+				os.Exit(1)
+			case err != nil:
+				fmt.Fprintf(os.Stderr, "%s: could not fetch overview, retrying in %s: %v\n",
+					time.Now().Format(time.RFC3339), interval, err)
+			case overview.LastUpdated != last:
+				if err := appendSnapshot(path, overview); err != nil {
+					fmt.Fprintf(os.Stderr, "could not record snapshot: %v\n", err)
+					os.Exit(1)
+				}
+				last = overview.LastUpdated
+				fmt.Fprintf(os.Stderr, "recorded %d prices (updated %s)\n",
+					len(overview.Data), common.EpochToISO8601(overview.LastUpdated))
+			}
+			if once {
+				return
+			}
+			time.Sleep(interval)
+		}
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history [item id or name]",
+	Short: "Show recorded price history for an item",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		bucketFlag, _ := cmd.Flags().GetString("bucket")
+		asCSV, _ := cmd.Flags().GetBool("csv")
+
+		since, err := common.ParseDuration(sinceFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --since: %v\n", err)
+			os.Exit(1)
+		}
+		bucket, err := common.ParseDuration(bucketFlag)
+		if err != nil || bucket <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: invalid --bucket %q\n", bucketFlag)
+			os.Exit(1)
+		}
+
+		items, _ := loadItemNames()
+		item, err := common.FindItem(items, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		snaps, err := readSnapshots(historyPath(cmd), time.Now().Add(-since))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not read history: %v\n", err)
+			os.Exit(1)
+		}
+		buckets := bucketize(snaps, item.ID, bucket)
+		if len(buckets) == 0 {
+			fmt.Printf("no recorded prices for %s in the last %s\n", item.Name, sinceFlag)
+			return
+		}
+
+		if asCSV {
+			w := csv.NewWriter(os.Stdout)
+			w.Write([]string{"start", "min", "max", "avg", "close", "samples"})
+			for _, b := range buckets {
+				w.Write([]string{
+					b.Start.Format(time.RFC3339),
+					strconv.FormatInt(b.Min, 10),
+					strconv.FormatInt(b.Max, 10),
+					strconv.FormatInt(b.Avg(), 10),
+					strconv.FormatInt(b.Close, 10),
+					strconv.Itoa(b.Count),
+				})
+			}
+			w.Flush()
+			return
+		}
+
+		fmt.Printf("%s (%d), last %s\n\n", item.Name, item.ID, sinceFlag)
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "START\tMIN\tMAX\tAVG\tCLOSE\tSAMPLES")
+		closes := make([]int64, 0, len(buckets))
+		for _, b := range buckets {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n",
+				b.Start.Format(time.RFC3339),
+				common.FormatPrice(b.Min), common.FormatPrice(b.Max),
+				common.FormatPrice(b.Avg()), common.FormatPrice(b.Close), b.Count)
+			closes = append(closes, b.Close)
+		}
+		w.Flush()
+		fmt.Printf("\n%s\n", sparkline(closes))
+	},
+}
+
+// historyPath resolves the --file flag of a history command.
+func historyPath(cmd *cobra.Command) string {
+	file, _ := cmd.Flags().GetString("file")
+	path, err := common.ResolveFilePath(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not resolve file path: %v\n", err)
+		os.Exit(1)
+	}
+	return path
+}
+
+// appendSnapshot writes overview as a single line at the end of path. A
+// half-written last line, left by an interrupted append, is terminated first
+// so the new snapshot stays readable.
+func appendSnapshot(path string, overview OverviewResponse) error {
+	line, err := json.Marshal(snapshot{RecordedAt: time.Now().UnixMilli(), OverviewResponse: overview})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readSnapshots returns the snapshots recorded at or after since, oldest first.
+// A missing file yields no snapshots. Lines that cannot be parsed, such as
+// one cut short by an interrupted record, are reported on stderr and skipped.
+func readSnapshots(path string, since time.Time) ([]snapshot, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var snaps []snapshot
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var s snapshot
+		if err := json.Unmarshal([]byte(text), &s); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping %s line %d: %v\n", path, line, err)
+			continue
+		}
+		if common.EpochToTime(s.RecordedAt).Before(since) {
+			continue
+		}
+		snaps = append(snaps, s)
+	}
+	return snaps, sc.Err()
+}
+
+// bucketize groups the item's recorded values into buckets of the given size.
+func bucketize(snaps []snapshot, itemID int, size time.Duration) []historyBucket {
+	var buckets []historyBucket
+	for _, s := range snaps {
+		v, ok := s.Price(itemID)
+		if !ok {
+			continue
+		}
+		start := common.EpochToTime(s.RecordedAt).Truncate(size)
+		if n := len(buckets); n == 0 || !buckets[n-1].Start.Equal(start) {
+			buckets = append(buckets, historyBucket{Start: start, Min: v, Max: v})
+		}
+		b := &buckets[len(buckets)-1]
+		b.Min = min(b.Min, v)
+		b.Max = max(b.Max, v)
+		b.Sum += v
+		b.Close = v
+		b.Count++
+	}
+	return buckets
+}
+
+// sparkline renders values as a row of Unicode block characters.
+func sparkline(values []int64) string {
+	const ticks = "▁▂▃▄▅▆▇█"
+	runes := []rune(ticks)
+	lo, hi := int64(math.MaxInt64), int64(math.MinInt64)
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int(float64(v-lo) / float64(hi-lo) * float64(len(runes)-1))
+		}
+		b.WriteRune(runes[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,171 @@
+package market
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	mineCmd.Flags().Int64("step", 1, "how much to undercut the cheapest competitor by")
+	mineCmd.Flags().Bool("watch", false, "keep polling and report when a listing gets undercut")
+	mineCmd.Flags().Duration("interval", 5*time.Minute, "time between polls with --watch")
+	mineCmd.Flags().Bool("ids", false, "show competitor IDs without resolving their names")
+	Cmd.AddCommand(mineCmd)
+}
+
+// position describes where one of a seller's listings stands in its order book.
+type position struct {
+	listing      Listing
+	rank         int   // 1 = cheapest listing in the book
+	unitsCheaper int64 // competitor units listed below our price
+	competitor   *Listing
+}
+
+// undercut reports whether a competitor is cheaper than the listing.
+func (p position) undercut() bool {
+	return p.competitor != nil && p.competitor.Price < p.listing.Price
+}
+
+// suggest returns the price that would make the listing the cheapest,
+// or its current price when it already is.
+func (p position) suggest(step int64) int64 {
+	if p.competitor == nil || p.competitor.Price > p.listing.Price {
+		return p.listing.Price
+	}
+	return max(p.competitor.Price-step, 1)
+}
+
+// positionIn locates l in book, which must include l.
+func positionIn(l Listing, book orderBook) position {
+	p := position{listing: l, rank: 1}
+	for i, other := range book {
+		if other.BcID == l.BcID {
+			continue
+		}
+		if other.Price < l.Price {
+			p.rank++
+			p.unitsCheaper += other.Amount
+		}
+		if p.competitor == nil {
+			p.competitor = &book[i]
+		}
+	}
+	return p
+}
+
+var mineCmd = &cobra.Command{
+	Use:   "mine [bcId]",
+	Short: "Compare a player's listings with the competition",
+	Long: `For each of a player's listings, show its rank by price in the item's
+order book, how many competitor units are listed cheaper, the cheapest
+competitor and a suggested price to become the cheapest again.
+
+With --watch the listings are polled and every new undercut is reported. A
+failed poll is logged and retried on the next one.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		bcID := common.ParseID(args[0])
+		step, _ := cmd.Flags().GetInt64("step")
+		watch, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		idsOnly, _ := cmd.Flags().GetBool("ids")
+
+		_, nameByID := loadItemNames()
+		sellers := make(sellerNames)
+		sellerLabel := func(id int64) string {
+			if idsOnly {
+				return fmt.Sprint(id)
+			}
+			return sellers.label(id)
+		}
+
+		positions, err := minePositions(bcID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching listings: %v\n", err)
+			os.Exit(1)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ITEM\tPRICE\tAMOUNT\tRANK\tUNITS CHEAPER\tCHEAPEST COMPETITOR\tSUGGEST")
+		for _, p := range positions {
+			competitor := "-"
+			if p.competitor != nil {
+				competitor = fmt.Sprintf("%s by %s", common.FormatPrice(p.competitor.Price), sellerLabel(p.competitor.BcID))
+			}
+			suggest := "keep"
+			if p.undercut() {
+				suggest = common.FormatPrice(p.suggest(step))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%s\n",
+				itemLabel(nameByID, p.listing.ItemID), common.FormatPrice(p.listing.Price),
+				p.listing.Amount, p.rank, p.unitsCheaper, competitor, suggest)
+		}
+		w.Flush()
+
+		if !watch {
+			return
+		}
+
+		// remember the cheapest competitor price we have already reported
+		reported := make(map[int64]int64)
+		for _, p := range positions {
+			if p.undercut() {
+				reported[p.listing.ID] = p.competitor.Price
+			}
+		}
+		for {
+			time.Sleep(interval)
+			positions, err := minePositions(bcID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: poll failed, retrying in %s: %v\n",
+					time.Now().Format(time.RFC3339), interval, err)
+				continue
+			}
+			for _, p := range positions {
+				if !p.undercut() {
+					delete(reported, p.listing.ID)
+					continue
+				}
+				if last, ok := reported[p.listing.ID]; ok && last <= p.competitor.Price {
+					continue
+				}
+				reported[p.listing.ID] = p.competitor.Price
+				fmt.Printf("%s UNDERCUT: %s listed at %s, %s by %s (suggest %s)\n",
+					time.Now().UTC().Format(time.RFC3339),
+					itemLabel(nameByID, p.listing.ItemID), common.FormatPrice(p.listing.Price),
+					common.FormatPrice(p.competitor.Price), sellerLabel(p.competitor.BcID),
+					common.FormatPrice(p.suggest(step)))
+			}
+		}
+	},
+}
+
+// minePositions fetches a player's listings and locates each in its order
+// book. Any failed request fails the whole poll, so a missing book is never
+// mistaken for a cleared undercut.
+func minePositions(bcID int) ([]position, error) {
+	var mine []Listing
+	if err := fetchInto(map[string]interface{}{"type": "userMarketListings", "id": bcID}, &mine); err != nil {
+		return nil, err
+	}
+	books := make(map[int]orderBook)
+	var positions []position
+	for _, l := range mine {
+		book, ok := books[l.ItemID]
+		if !ok {
+			var listings []Listing
+			if err := fetchInto(map[string]interface{}{"type": "marketListings", "itemId": l.ItemID}, &listings); err != nil {
+				return nil, fmt.Errorf("listings for item %d: %w", l.ItemID, err)
+			}
+			book = newOrderBook(listings)
+			books[l.ItemID] = book
+		}
+		positions = append(positions, positionIn(l, book))
+	}
+	return positions, nil
+}
@@ -0,0 +1,296 @@
+package market
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	sellersCmd.Flags().StringSlice("items", nil, "item IDs or names to analyse")
+	sellersCmd.Flags().Bool("all", false, "analyse every traded item")
+	sellersCmd.Flags().StringP("sort", "s", "value", "rank sellers by: value, units, or items")
+	sellersCmd.Flags().IntP("limit", "l", 25, "maximum number of sellers to show (0 = all)")
+	sellersCmd.Flags().Float64("dominant", 50, "flag items where one seller holds at least this percentage of the units")
+	sellersCmd.Flags().Int64Slice("seller", nil, "list every item of these seller bcIds with their share of it")
+	sellersCmd.Flags().Bool("breakdown", false, "list every item of each ranked seller with their share of it")
+	sellersCmd.Flags().Bool("ids", false, "show seller IDs without resolving their names")
+	sellersCmd.Flags().Int("workers", 4, "concurrent listing and profile requests")
+	sellersCmd.Flags().Float64("rate", 5, "maximum listing and profile requests per second (0 = unlimited)")
+	Cmd.AddCommand(sellersCmd)
+}
+
+// sellerStats aggregates the listings of one seller.
+type sellerStats struct {
+	bcID  int64
+	value int64 // price * amount over all listings
+	units int64
+	items map[int]int64 // units listed per item
+	worth map[int]int64 // price * amount listed per item
+}
+
+// itemShare is the supply of one item and its largest seller.
+type itemShare struct {
+	itemID   int
+	units    int64
+	sellers  int
+	top      int64 // bcId of the seller with the most units
+	topUnits int64
+}
+
+// share returns the top seller's percentage of the item's units.
+func (s itemShare) share() float64 {
+	if s.units == 0 {
+		return 0
+	}
+	return float64(s.topUnits) / float64(s.units) * 100
+}
+
+var sellersCmd = &cobra.Command{
+	Use:   "sellers",
+	Short: "Rank sellers by listed value and show market share per item",
+	Long: `Pull the listings of the chosen items (--items) or of every traded item
+(--all), rank sellers by listed value, unit count or number of items, and
+show each item's largest seller with their share of its supply. Items where
+one seller holds at least --dominant percent of the units are flagged.
+
+--seller lists every item of the given sellers with their share of its
+supply; --breakdown does the same for every ranked seller.
+
+Seller names are looked up through the same --workers and --rate as the
+listings; --ids skips the lookups.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		itemArgs, _ := cmd.Flags().GetStringSlice("items")
+		all, _ := cmd.Flags().GetBool("all")
+		sortField, _ := cmd.Flags().GetString("sort")
+		limit, _ := cmd.Flags().GetInt("limit")
+		dominant, _ := cmd.Flags().GetFloat64("dominant")
+		idsOnly, _ := cmd.Flags().GetBool("ids")
+		workers, _ := cmd.Flags().GetInt("workers")
+		rate, _ := cmd.Flags().GetFloat64("rate")
+		breakdownIDs, _ := cmd.Flags().GetInt64Slice("seller")
+		breakdownAll, _ := cmd.Flags().GetBool("breakdown")
+
+		if all == (len(itemArgs) > 0) {
+			fmt.Fprintln(os.Stderr, "Error: specify either --items or --all")
+			os.Exit(1)
+		}
+
+		items, nameByID := loadItemNames()
+		var ids []int
+		if all {
+			overview := FetchOverview()
+			for _, it := range items {
+				if _, traded := overview.Price(it.ID); traded {
+					ids = append(ids, it.ID)
+				}
+			}
+			fmt.Fprintf(os.Stderr, "scanning %d items...\n", len(ids))
+		} else {
+			for _, arg := range itemArgs {
+				it, err := common.FindItem(items, arg)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				if !slices.Contains(ids, it.ID) {
+					ids = append(ids, it.ID)
+				}
+			}
+		}
+		books, _ := fetchAllListings(ids, workers, rate)
+
+		// aggregate per seller and per item
+		stats := make(map[int64]*sellerStats)
+		supply := make(map[int]int64)
+		sellerCount := make(map[int]int)
+		var shares []itemShare
+		for _, id := range ids {
+			share := itemShare{itemID: id}
+			perSeller := make(map[int64]int64)
+			for _, l := range books[id] {
+				s, ok := stats[l.BcID]
+				if !ok {
+					s = &sellerStats{bcID: l.BcID, items: make(map[int]int64), worth: make(map[int]int64)}
+					stats[l.BcID] = s
+				}
+				s.value += l.Price * l.Amount
+				s.units += l.Amount
+				s.items[id] += l.Amount
+				s.worth[id] += l.Price * l.Amount
+				perSeller[l.BcID] += l.Amount
+				share.units += l.Amount
+			}
+			supply[id] = share.units
+			share.sellers = len(perSeller)
+			sellerCount[id] = share.sellers
+			for bcID, units := range perSeller {
+				if units > share.topUnits || (units == share.topUnits && bcID < share.top) {
+					share.top, share.topUnits = bcID, units
+				}
+			}
+			if share.units > 0 {
+				shares = append(shares, share)
+			}
+		}
+
+		ranked := make([]*sellerStats, 0, len(stats))
+		for _, s := range stats {
+			ranked = append(ranked, s)
+		}
+		var key func(s *sellerStats) int64
+		switch sortField {
+		case "value":
+			key = func(s *sellerStats) int64 { return s.value }
+		case "units":
+			key = func(s *sellerStats) int64 { return s.units }
+		case "items":
+			key = func(s *sellerStats) int64 { return int64(len(s.items)) }
+		default:
+			fmt.Fprintf(os.Stderr, "invalid sort option: %s (must be value, units, or items)\n", sortField)
+			os.Exit(1)
+		}
+		sort.Slice(ranked, func(i, j int) bool {
+			if key(ranked[i]) != key(ranked[j]) {
+				return key(ranked[i]) > key(ranked[j])
+			}
+			return ranked[i].bcID < ranked[j].bcID
+		})
+		if limit > 0 && len(ranked) > limit {
+			ranked = ranked[:limit]
+		}
+
+		var breakdown []int64
+		if breakdownAll {
+			for _, s := range ranked {
+				breakdown = append(breakdown, s.bcID)
+			}
+		}
+		for _, id := range breakdownIDs {
+			if !slices.Contains(breakdown, id) {
+				breakdown = append(breakdown, id)
+			}
+		}
+
+		// resolve the names of every printed seller up front, through the
+		// same worker pool and rate limit as the listings
+		sellers := make(sellerNames)
+		if !idsOnly {
+			var printed []int64
+			for _, s := range ranked {
+				printed = append(printed, s.bcID)
+			}
+			printed = append(printed, breakdown...)
+			for _, s := range shares {
+				printed = append(printed, s.top)
+			}
+			fmt.Fprintln(os.Stderr, "resolving seller names...")
+			sellers.resolve(printed, 0, workers, rate)
+		}
+		sellerLabel := func(bcID int64) string {
+			if idsOnly {
+				return strconv.FormatInt(bcID, 10)
+			}
+			return sellers.label(bcID)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "RANK\tSELLER\tVALUE\tUNITS\tITEMS\tLARGEST SHARE")
+		for i, s := range ranked {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n",
+				i+1, sellerLabel(s.bcID), common.FormatPrice(s.value), s.units, len(s.items),
+				largestShare(s, supply, nameByID))
+		}
+		w.Flush()
+
+		// largest seller of every item, most concentrated first
+		sort.Slice(shares, func(i, j int) bool {
+			if shares[i].share() != shares[j].share() {
+				return shares[i].share() > shares[j].share()
+			}
+			return shares[i].itemID < shares[j].itemID
+		})
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ITEM\tUNITS\tSELLERS\tTOP SELLER\tSHARE\t")
+		dominated := 0
+		for _, s := range shares {
+			flag := ""
+			if s.share() >= dominant {
+				flag = "DOMINANT"
+				dominated++
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%.1f%%\t%s\n",
+				itemLabel(nameByID, s.itemID), s.units, s.sellers, sellerLabel(s.top), s.share(), flag)
+		}
+		w.Flush()
+		fmt.Printf("\n%d sellers across %d items, %d dominated by a single seller\n",
+			len(stats), len(shares), dominated)
+
+		// per-seller market share of every item
+		for _, bcID := range breakdown {
+			fmt.Printf("\n%s\n", sellerLabel(bcID))
+			s, ok := stats[bcID]
+			if !ok {
+				fmt.Println("no listings among the analysed items")
+				continue
+			}
+			printSellerShares(s, supply, sellerCount, nameByID)
+		}
+	},
+}
+
+// printSellerShares lists every item of a seller with their share of its
+// listed units, largest share first.
+func printSellerShares(s *sellerStats, supply map[int]int64, sellerCount map[int]int, nameByID map[int]string) {
+	ids := make([]int, 0, len(s.items))
+	for id := range s.items {
+		ids = append(ids, id)
+	}
+	pct := func(id int) float64 {
+		if supply[id] == 0 {
+			return 0
+		}
+		return float64(s.items[id]) / float64(supply[id]) * 100
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if pct(ids[i]) != pct(ids[j]) {
+			return pct(ids[i]) > pct(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ITEM\tUNITS\tSUPPLY\tSHARE\tVALUE\tSELLERS")
+	for _, id := range ids {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\t%s\t%d\n",
+			itemLabel(nameByID, id), s.items[id], supply[id], pct(id), common.FormatPrice(s.worth[id]), sellerCount[id])
+	}
+	w.Flush()
+}
+
+// largestShare describes the item where the seller holds the largest share
+// of the listed units.
+func largestShare(s *sellerStats, supply map[int]int64, nameByID map[int]string) string {
+	best, bestID := 0.0, 0
+	for id, units := range s.items {
+		if supply[id] == 0 {
+			continue
+		}
+		pct := float64(units) / float64(supply[id]) * 100
+		if pct > best || (pct == best && id < bestID) {
+			best, bestID = pct, id
+		}
+	}
+	if best == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%% of %s", best, itemLabel(nameByID, bestID))
+}
@@ -0,0 +1,206 @@
+package pet
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+	"bcncli/profile"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(breedPlanCmd)
+
+	breedPlanCmd.Flags().Duration("cooldown", 24*time.Hour, "breeding cooldown of a pet (assumed, not from game data)")
+	breedPlanCmd.Flags().Int("daily-limit", 5, "breedings allowed per day (assumed, not from game data)")
+	breedPlanCmd.Flags().Int64("cost", 0, "base breeding cost in BC (0 = unknown)")
+	breedPlanCmd.Flags().Float64("perk-discount", 5, "breeding cost reduction per LowerPetBreedCost perk level, in percent (assumed)")
+	breedPlanCmd.Flags().StringToInt("goals", map[string]int{"tier": 1, "aura": 1, "category": 1}, "goal weights: tier, aura, skin, category")
+	breedPlanCmd.Flags().IntP("limit", "l", 10, "maximum number of pairs to suggest")
+}
+
+// breedGoals scores one aspect of a pair between 0 and 1.
+var breedGoals = map[string]func(a, b Pet, maxTier int) float64{
+	"tier": func(a, b Pet, maxTier int) float64 {
+		if maxTier == 0 {
+			return 0
+		}
+		return float64(a.Tier+b.Tier) / float64(2*maxTier)
+	},
+	"aura": func(a, b Pet, _ int) float64 {
+		return (boolScore(a.Aura != "") + boolScore(b.Aura != "")) / 2
+	},
+	"skin": func(a, b Pet, _ int) float64 {
+		return (boolScore(a.Skin != "") + boolScore(b.Skin != "")) / 2
+	},
+	"category": func(a, b Pet, _ int) float64 {
+		ca := common.GetPetCategory(a.Species)
+		return boolScore(ca != "" && ca == common.GetPetCategory(b.Species))
+	},
+}
+
+// breedPair is a suggested pairing with its score.
+type breedPair struct {
+	a, b  Pet
+	score float64
+	ready time.Time // when both pets are off cooldown
+}
+
+var breedPlanCmd = &cobra.Command{
+	Use:   "breed-plan [userId]",
+	Short: "Suggest breeding pairs among a player's pets",
+	Long: `List when each of a player's pets can breed again and suggest pairings,
+ranked by weighted goals:
+
+  tier      higher tier pets
+  aura      pets with an aura
+  skin      pets with a skin
+  category  both pets of the same adventure category
+
+Each pet is used in at most one pair. Pairs that can breed now are marked
+until the player's remaining daily breeds are used up.
+
+The API does not publish the breeding rules, so the defaults of --cooldown
+(24h), --daily-limit (5) and --perk-discount (5% per level) are assumptions.
+Override them when the game's numbers are known. The breeding cost is only
+priced when --cost is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		userID := common.ParseID(args[0])
+		cooldown, _ := cmd.Flags().GetDuration("cooldown")
+		dailyLimit, _ := cmd.Flags().GetInt("daily-limit")
+		cost, _ := cmd.Flags().GetInt64("cost")
+		discount, _ := cmd.Flags().GetFloat64("perk-discount")
+		goals, _ := cmd.Flags().GetStringToInt("goals")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		for g := range goals {
+			if _, ok := breedGoals[g]; !ok {
+				fmt.Fprintf(os.Stderr, "Invalid goal: %s (must be tier, aura, skin, or category)\n", g)
+				os.Exit(1)
+			}
+		}
+
+		p := profile.Fetch(userID)
+		pets := fetchOwnedPets(userID)
+		now := time.Now()
+
+		readyAt := make(map[int64]time.Time, len(pets))
+		maxTier := 0
+		for _, pt := range pets {
+			readyAt[pt.ID] = breedReady(pt, cooldown)
+			maxTier = max(maxTier, pt.Tier)
+		}
+		sort.Slice(pets, func(i, j int) bool {
+			if !readyAt[pets[i].ID].Equal(readyAt[pets[j].ID]) {
+				return readyAt[pets[i].ID].Before(readyAt[pets[j].ID])
+			}
+			return pets[i].ID < pets[j].ID
+		})
+
+		fmt.Printf("%s (%d)\n\n", p.Name, p.ID)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tName\tSpecies\tTier\tGen\tBred\tSkin\tAura\tReady")
+		for _, pt := range pets {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\n",
+				pt.ID, pt.Name, pt.Species, pt.Tier, pt.Generation, pt.TimesBred,
+				orDash(pt.Skin), orDash(pt.Aura), readyLabel(readyAt[pt.ID], now))
+		}
+		w.Flush()
+
+		left := max(dailyLimit-p.PetsBredDaily, 0)
+		fmt.Printf("\nBred today: %d of %d, %d left\n", p.PetsBredDaily, dailyLimit, left)
+		level := p.Perks.LowerPetBreedCost
+		pct := min(float64(level)*discount, 100)
+		if cost > 0 {
+			fmt.Printf("Breeding cost: %s (LowerPetBreedCost level %d, -%.0f%%)\n",
+				common.FormatPrice(int64(float64(cost)*(100-pct)/100)), level, pct)
+		} else {
+			fmt.Printf("Breeding cost: -%.0f%% (LowerPetBreedCost level %d)\n", pct, level)
+		}
+
+		// score every pair, then pick the best disjoint ones
+		var pairs []breedPair
+		for i := range pets {
+			for j := i + 1; j < len(pets); j++ {
+				a, b := pets[i], pets[j]
+				var score float64
+				for g, weight := range goals {
+					score += float64(weight) * breedGoals[g](a, b, maxTier)
+				}
+				ready := readyAt[a.ID]
+				if readyAt[b.ID].After(ready) {
+					ready = readyAt[b.ID]
+				}
+				pairs = append(pairs, breedPair{a: a, b: b, score: score, ready: ready})
+			}
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			if pairs[i].score != pairs[j].score {
+				return pairs[i].score > pairs[j].score
+			}
+			return pairs[i].ready.Before(pairs[j].ready)
+		})
+		used := make(map[int64]bool)
+		var plan []breedPair
+		for _, pr := range pairs {
+			if len(plan) == limit {
+				break
+			}
+			if used[pr.a.ID] || used[pr.b.ID] {
+				continue
+			}
+			used[pr.a.ID], used[pr.b.ID] = true, true
+			plan = append(plan, pr)
+		}
+
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Pet A\tPet B\tScore\tReady\tToday")
+		for _, pr := range plan {
+			today := "no"
+			if !pr.ready.After(now) && left > 0 {
+				today = "yes"
+				left--
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\t%s\n",
+				breedLabel(pr.a), breedLabel(pr.b), pr.score, readyLabel(pr.ready, now), today)
+		}
+		w.Flush()
+	},
+}
+
+// breedReady returns when a pet is off its breeding cooldown.
+func breedReady(p Pet, cooldown time.Duration) time.Time {
+	last, err := time.Parse(time.RFC3339, p.LastBred)
+	if err != nil {
+		return time.Time{}
+	}
+	return last.Add(cooldown)
+}
+
+// readyLabel renders a readiness time relative to now.
+func readyLabel(t, now time.Time) string {
+	if !t.After(now) {
+		return "now"
+	}
+	return "in " + common.FormatDuration(t.Sub(now))
+}
+
+// breedLabel describes a pet in a pairing.
+func breedLabel(p Pet) string {
+	return fmt.Sprintf("%s (%d) %s T%d", p.Name, p.ID, p.Species, p.Tier)
+}
+
+// boolScore converts b to 1 or 0.
+func boolScore(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,246 @@
+package pet
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+	"bcncli/search"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(collectionCmd)
+
+	collectionCmd.Flags().String("category", "", "only show this category (Fish, Hunt, Explore, Mine)")
+	collectionCmd.Flags().Int("owners", 3, "owners to list per missing combination")
+	collectionCmd.Flags().Bool("owned-only", false, "only count species without searching for skin and aura combinations")
+	collectionCmd.Flags().Float64("rate", 5, "maximum search requests per second (0 = unlimited)")
+}
+
+// petCombo is a skin and aura combination of a species.
+type petCombo struct {
+	skin, aura string
+}
+
+func (c petCombo) String() string {
+	skin, aura := c.skin, c.aura
+	if skin == "" {
+		skin = "no skin"
+	}
+	if aura == "" {
+		aura = "no aura"
+	}
+	return skin + " / " + aura
+}
+
+// comboStats counts the pets of one combination found game-wide.
+type comboStats struct {
+	count  int
+	owners map[int64]int
+}
+
+// topOwners returns up to n owners with the most pets, as "bcId×count".
+func (s comboStats) topOwners(n int) string {
+	ids := make([]int64, 0, len(s.owners))
+	for id := range s.owners {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if s.owners[ids[i]] != s.owners[ids[j]] {
+			return s.owners[ids[i]] > s.owners[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	var parts []string
+	for _, id := range ids[:min(n, len(ids))] {
+		parts = append(parts, fmt.Sprintf("%d×%d", id, s.owners[id]))
+	}
+	if len(ids) > n {
+		parts = append(parts, fmt.Sprintf("+%d more", len(ids)-n))
+	}
+	return strings.Join(parts, ", ")
+}
+
+var collectionCmd = &cobra.Command{
+	Use:   "collection [userId]",
+	Short: "Show which species, skins and auras a player owns",
+	Long: `Compare a player's pets with every species in the game, and with the
+skin and aura combinations found by searching each species, as a completion
+grid per category. Missing combinations are listed with how many pets of
+them the search returned game-wide and who owns them.
+
+Search results may be capped by the API, so game-wide counts are a lower
+bound. A species whose search fails is reported, its combinations are shown
+as unknown and left out of the totals, and the rest of the grid is kept.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		userID := common.ParseID(args[0])
+		category, _ := cmd.Flags().GetString("category")
+		ownerCount, _ := cmd.Flags().GetInt("owners")
+		ownedOnly, _ := cmd.Flags().GetBool("owned-only")
+		rate, _ := cmd.Flags().GetFloat64("rate")
+
+		var tick <-chan time.Time
+		if rate > 0 && !ownedOnly {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		owned := make(map[string]map[petCombo]int)
+		for _, p := range fetchOwnedPets(userID) {
+			species := strings.ToLower(p.Species)
+			if owned[species] == nil {
+				owned[species] = make(map[petCombo]int)
+			}
+			owned[species][petCombo{p.Skin, p.Aura}]++
+		}
+
+		type missing struct {
+			species string
+			combo   petCombo
+			stats   comboStats
+		}
+		var missingCombos []missing
+		var totalSpecies, ownedSpecies, totalCombos, ownedCombos, unknownSpecies int
+
+		for _, cat := range adventureCategories {
+			if category != "" && !strings.EqualFold(category, cat) {
+				continue
+			}
+			fmt.Println(cat)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Species\tOwned\tCombos\tCompletion")
+			for _, pd := range common.GetPetsByCategory(cat) {
+				mine := owned[strings.ToLower(pd.Name)]
+				totalSpecies++
+				count := 0
+				for _, n := range mine {
+					count += n
+				}
+				if count > 0 {
+					ownedSpecies++
+				}
+				if ownedOnly {
+					fmt.Fprintf(w, "%s\t%d\t-\t%s\n", speciesLabel(pd.Name), count, checkMark(count > 0))
+					continue
+				}
+
+				if tick != nil {
+					<-tick
+				}
+				game, err := searchCombos(pd.Name)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: search for %s failed: %v\n", pd.Name, err)
+					unknownSpecies++
+					fmt.Fprintf(w, "%s\t%d\t?\tunknown\n", speciesLabel(pd.Name), count)
+					continue
+				}
+				// combinations the player owns always count, even when the
+				// search did not return them
+				for c := range mine {
+					if _, ok := game[c]; !ok {
+						game[c] = comboStats{owners: map[int64]int{}}
+					}
+				}
+				have := 0
+				for _, c := range sortedCombos(game) {
+					if mine[c] > 0 {
+						have++
+						continue
+					}
+					missingCombos = append(missingCombos, missing{pd.Name, c, game[c]})
+				}
+				totalCombos += len(game)
+				ownedCombos += have
+				fmt.Fprintf(w, "%s\t%d\t%d/%d\t%s\n",
+					speciesLabel(pd.Name), count, have, len(game), completionBar(have, len(game)))
+			}
+			w.Flush()
+			fmt.Println()
+		}
+
+		fmt.Printf("Species: %d/%d\n", ownedSpecies, totalSpecies)
+		if ownedOnly {
+			return
+		}
+		fmt.Printf("Skin and aura combinations: %d/%d\n", ownedCombos, totalCombos)
+		if unknownSpecies > 0 {
+			fmt.Printf("Unknown: %d species whose search failed are not counted\n", unknownSpecies)
+		}
+		if len(missingCombos) == 0 {
+			return
+		}
+
+		fmt.Println("\nMissing combinations")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Species\tSkin / Aura\tGame-wide\tOwners")
+		for _, m := range missingCombos {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", m.species, m.combo, m.stats.count, m.stats.topOwners(ownerCount))
+		}
+		w.Flush()
+	},
+}
+
+// searchCombos searches all pets of a species and counts them per skin and
+// aura combination.
+func searchCombos(species string) (map[petCombo]comboStats, error) {
+	raw, err := search.SearchPets("any skin", "any aura", species, "")
+	if err != nil {
+		return nil, err
+	}
+	pets, err := decodePetList(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing search results: %w", err)
+	}
+	combos := make(map[petCombo]comboStats)
+	for _, p := range pets {
+		c := petCombo{p.Skin, p.Aura}
+		s, ok := combos[c]
+		if !ok {
+			s = comboStats{owners: make(map[int64]int)}
+		}
+		s.count++
+		s.owners[p.OwnerBCID]++
+		combos[c] = s
+	}
+	return combos, nil
+}
+
+// sortedCombos returns the combinations ordered by skin, then aura.
+func sortedCombos(m map[petCombo]comboStats) []petCombo {
+	combos := make([]petCombo, 0, len(m))
+	for c := range m {
+		combos = append(combos, c)
+	}
+	sort.Slice(combos, func(i, j int) bool {
+		if combos[i].skin != combos[j].skin {
+			return combos[i].skin < combos[j].skin
+		}
+		return combos[i].aura < combos[j].aura
+	})
+	return combos
+}
+
+// completionBar renders have out of total as a ten-cell bar with a percentage.
+func completionBar(have, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	filled := have * 10 / total
+	return fmt.Sprintf("%s%s %3d%%", strings.Repeat("█", filled), strings.Repeat("░", 10-filled), have*100/total)
+}
+
+// checkMark renders a yes/no cell.
+func checkMark(ok bool) string {
+	if ok {
+		return "✓"
+	}
+	return "✗"
+}
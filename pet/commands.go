@@ -4,8 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"sort"
-	"strings"
 	"text/tabwriter"
 
 	"bcncli/client"
@@ -60,28 +58,66 @@ func init() {
 	// Register subcommands
 	Cmd.AddCommand(infoCmd, ownedCmd, offspringCmd)
 
+	// Define flags for info command
+	infoCmd.Flags().Bool("debug", false, "Enable debug (JSON) output")
+
 	// Define flags for owned command
 	ownedCmd.Flags().Bool("debug", false, "Enable debug (JSON) output")
-	ownedCmd.Flags().String("sort", "", "Sort table by this field (id, name, species, tier, xp, adventure, items, boost)")
-	ownedCmd.Flags().String("group", "", "Group table by this field (species, tier, boost, adventure, craving)")
+	ownedCmd.Flags().String("sort", "", "Sort by fields, e.g. tier:desc,xp:desc")
+	ownedCmd.Flags().StringArrayP("where", "w", nil, "Filter expression, e.g. 'tier>=5 && aura!=\"\"' (repeatable)")
+	ownedCmd.Flags().String("columns", defaultPetColumns, "Comma-separated columns to show")
+	ownedCmd.Flags().String("group", "", "Group table by these fields, e.g. species or adventure,tier")
+	ownedCmd.Flags().Bool("summary", false, "With --group, only show the aggregates of each group")
 }
 
-var infoCmd = &cobra.Command{
-	Use:   "info [id]",
-	Short: "Fetch pet info",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id := common.ParseID(args[0])
-		payload := map[string]interface{}{"type": "pet", "id": id}
-		data := client.FetchDataOrExit(payload)
-		common.PrintJSON(data)
-	},
+// defaultPetColumns are the columns of pet owned without --columns.
+const defaultPetColumns = "id,name,species,tier,xp,adventure,items,boost,ends"
+
+// petFields returns the fields of a pet table. items resolves item names.
+func petFields(items []common.Item) Fields[Pet] {
+	itemName := func(id int64) string {
+		if id <= 0 {
+			return ""
+		}
+		return common.LookUpItemName(int(id), items)
+	}
+	return Fields[Pet]{
+		{Name: "id", Header: "ID", Value: func(p Pet) any { return p.ID }},
+		{Name: "name", Header: "Name", Value: func(p Pet) any { return p.Name }},
+		{Name: "species", Header: "Species", Value: func(p Pet) any { return p.Species }},
+		{Name: "category", Header: "Category", Value: func(p Pet) any { return common.GetPetCategory(p.Species) }},
+		{Name: "tier", Header: "Tier", Value: func(p Pet) any { return int64(p.Tier) }},
+		{Name: "xp", Header: "XP", Value: func(p Pet) any { return p.XP }},
+		{Name: "gen", Header: "Gen", Value: func(p Pet) any { return int64(p.Generation) }},
+		{Name: "parenta", Header: "Parent A", Value: func(p Pet) any { return p.ParentAID }},
+		{Name: "parentb", Header: "Parent B", Value: func(p Pet) any { return p.ParentBID }},
+		{Name: "bred", Header: "Bred", Value: func(p Pet) any { return int64(p.TimesBred) }},
+		{Name: "lastbred", Header: "Last Bred", Value: func(p Pet) any { return p.LastBred }},
+		{Name: "hatched", Header: "Hatched", Value: func(p Pet) any { return p.HatchDate }},
+		{Name: "held", Header: "Held", Value: func(p Pet) any { return itemName(p.HeldItemID) }},
+		{Name: "energy", Header: "Energy", Value: func(p Pet) any { return p.UnsyncedEnergy }},
+		{Name: "adventure", Header: "Adventure", Value: func(p Pet) any { return p.AdventureType }},
+		{Name: "items", Header: "Items", Value: func(p Pet) any { return p.LifetimeItemsFound }},
+		{Name: "boost", Header: "Boost", Value: func(p Pet) any { return int64(p.AdventureBoost.Multiplier) }},
+		{Name: "ends", Header: "Ends", Value: func(p Pet) any { return p.AdventureBoost.EndTime },
+			Format: func(p Pet) string { return common.EpochToISO8601(p.AdventureBoost.EndTime) }},
+		{Name: "craving", Header: "Craving", Value: func(p Pet) any { return itemName(p.Craving.ItemID) }},
+		{Name: "skin", Header: "Skin", Value: func(p Pet) any { return p.Skin }},
+		{Name: "aura", Header: "Aura", Value: func(p Pet) any { return p.Aura }},
+	}
 }
 
 var ownedCmd = &cobra.Command{
 	Use:   "owned [userId]",
 	Short: "List pets for a user",
-	Args:  cobra.ExactArgs(1),
+	Long: `List a player's pets. Every flag takes field names; run with an unknown
+field to see them all.
+
+  --sort tier:desc,xp:desc
+  --where 'tier>=5 && aura!=""'
+  --columns id,name,species,skin,aura,craving
+  --group adventure,tier --summary`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		userID := common.ParseID(args[0])
 		payload := map[string]interface{}{"type": "userPetsAndEggs", "id": userID}
@@ -107,104 +143,96 @@ var ownedCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// Sort
-		sortKey, _ := cmd.Flags().GetString("sort")
-		if sortKey != "" {
-			switch sortKeyLower := strings.ToLower(sortKey); sortKeyLower {
-			case "id":
-				sort.Slice(pets, func(i, j int) bool { return pets[i].ID < pets[j].ID })
-			case "name":
-				sort.Slice(pets, func(i, j int) bool { return pets[i].Name < pets[j].Name })
-			case "species":
-				sort.Slice(pets, func(i, j int) bool { return pets[i].Species < pets[j].Species })
-			case "tier":
-				sort.Slice(pets, func(i, j int) bool { return pets[i].Tier < pets[j].Tier })
-			case "xp":
-				sort.Slice(pets, func(i, j int) bool { return pets[i].XP < pets[j].XP })
-			case "adventure":
-				sort.Slice(pets, func(i, j int) bool { return pets[i].AdventureType < pets[j].AdventureType })
-			case "items":
-				sort.Slice(pets, func(i, j int) bool { return pets[i].LifetimeItemsFound < pets[j].LifetimeItemsFound })
-			case "boost":
-				sort.Slice(pets, func(i, j int) bool { return pets[i].AdventureBoost.Multiplier < pets[j].AdventureBoost.Multiplier })
-			default:
-				fmt.Fprintf(os.Stderr, "Invalid sort field: %s\n", sortKey)
+		items, err := common.LoadItemData("itemid.json", 3600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not load item data: %v\n", err)
+			os.Exit(1)
+		}
+		fields := petFields(items)
+
+		// Filter, sort and pick columns
+		wheres, _ := cmd.Flags().GetStringArray("where")
+		pets, err = FilterRows(pets, fields, wheres)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --where: %v\n", err)
+			os.Exit(1)
+		}
+		if sortSpec, _ := cmd.Flags().GetString("sort"); sortSpec != "" {
+			if err := SortRows(pets, fields, sortSpec); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --sort: %v\n", err)
 				os.Exit(1)
 			}
 		}
+		columnSpec, _ := cmd.Flags().GetString("columns")
+		cols, err := fields.Select(columnSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --columns: %v\n", err)
+			os.Exit(1)
+		}
 
 		// Group
-		groupKey, _ := cmd.Flags().GetString("group")
-		if groupKey != "" {
-			// Build groups
-			groups := make(map[string][]Pet)
-			for _, p := range pets {
-				var key string
-				switch strings.ToLower(groupKey) {
-				case "species":
-					key = p.Species
-				case "tier":
-					key = fmt.Sprint(p.Tier)
-				case "boost":
-					key = fmt.Sprint(p.AdventureBoost.Multiplier)
-				case "adventure":
-					key = p.AdventureType
-				case "craving":
-					key = fmt.Sprint(p.Craving.ItemID)
-				default:
-					fmt.Fprintf(os.Stderr, "Invalid group field: %s\n", groupKey)
-					os.Exit(1)
-				}
-				groups[key] = append(groups[key], p)
-			}
-
-			// Sort group keys
-			var keys []string
-			for k := range groups {
-				keys = append(keys, k)
-			}
-			sort.Strings(keys)
-
-			// Print per group
-			for _, k := range keys {
-				items := groups[k]
-				fmt.Printf("%s (%d)\n", k, len(items))
-
-				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-				fmt.Fprintln(w, "ID\tName\tSpecies\tTier\tXP\tAdventure\tItems\tBoost\tEnds")
-				for _, p := range items {
-					fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\t%d\t%d\t%s\n",
-						p.ID, p.Name, p.Species, p.Tier, p.XP,
-						p.AdventureType, p.LifetimeItemsFound,
-						p.AdventureBoost.Multiplier, common.EpochToISO8601(p.AdventureBoost.EndTime))
-				}
-				w.Flush()
-				fmt.Println()
-			}
+		groupSpec, _ := cmd.Flags().GetString("group")
+		if groupSpec == "" {
+			PrintRows(os.Stdout, pets, cols)
 			return
 		}
-
-		// Default table
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tName\tSpecies\tTier\tXP\tAdventure\tItems\tBoost\tEnds")
-		for _, p := range pets {
-			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\t%d\t%d\t%s\n",
-				p.ID, p.Name, p.Species, p.Tier, p.XP,
-				p.AdventureType, p.LifetimeItemsFound,
-				p.AdventureBoost.Multiplier, common.EpochToISO8601(p.AdventureBoost.EndTime))
+		groups, err := GroupRows(pets, fields, groupSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --group: %v\n", err)
+			os.Exit(1)
+		}
+		if summary, _ := cmd.Flags().GetBool("summary"); summary {
+			printPetGroupSummary(groups)
+			return
+		}
+		for _, g := range groups {
+			count, avgTier, items := petAggregates(g.Rows)
+			fmt.Printf("%s (%d, avg tier %.1f, %d items)\n", g.Key, count, avgTier, items)
+			PrintRows(os.Stdout, g.Rows, cols)
+			fmt.Println()
 		}
-		w.Flush()
 	},
 }
 
-var offspringCmd = &cobra.Command{
-	Use:   "offspring [id]",
-	Short: "Fetch offspring",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id := common.ParseID(args[0])
-		payload := map[string]interface{}{"type": "petOffspring", "id": id}
-		data := client.FetchDataOrExit(payload)
-		common.PrintJSON(data)
-	},
+// petAggregates returns the count, average tier and total items found of pets.
+func petAggregates(pets []Pet) (int, float64, int64) {
+	var tiers, items int64
+	for _, p := range pets {
+		tiers += int64(p.Tier)
+		items += p.LifetimeItemsFound
+	}
+	if len(pets) == 0 {
+		return 0, 0, 0
+	}
+	return len(pets), float64(tiers) / float64(len(pets)), items
+}
+
+// printPetGroupSummary prints one aggregate row per group.
+func printPetGroupSummary(groups []Group[Pet]) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Group\tCount\tAvg Tier\tTotal Items")
+	var all []Pet
+	for _, g := range groups {
+		count, avgTier, items := petAggregates(g.Rows)
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t%d\n", g.Key, count, avgTier, items)
+		all = append(all, g.Rows...)
+	}
+	count, avgTier, items := petAggregates(all)
+	fmt.Fprintf(w, "Total\t%d\t%.1f\t%d\n", count, avgTier, items)
+	w.Flush()
 }
+
+// fetchOwnedPets fetches the pets of a player, exiting on error.
+func fetchOwnedPets(userID int) []Pet {
+	raw := client.FetchDataOrExit(map[string]interface{}{"type": "userPetsAndEggs", "id": userID})
+	var resp struct {
+		Pets []Pet `json:"pets"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing pets data: %v\n", err)
+		os.Exit(1)
+	}
+	return resp.Pets
+}
+
+var offspringCmd = NewOffspringCmd("Fetch offspring")
@@ -0,0 +1,26 @@
+package pet
+
+import "bcncli/common"
+
+// Egg represents an unhatched egg
+type Egg struct {
+	ID         int64  `json:"id"`
+	OwnerBCID  int64  `json:"ownerBcId"`
+	Species    string `json:"species"`
+	Tier       int    `json:"tier"`
+	Generation int    `json:"generation"`
+	ParentAID  int64  `json:"parentAId"`
+	ParentBID  int64  `json:"parentBId"`
+	HatchDate  string `json:"hatchDate"`
+	Skin       string `json:"skin"`
+	Aura       string `json:"aura"`
+}
+
+// HatchesIn returns the time left until the egg hatches, or "ready".
+func (e Egg) HatchesIn() string {
+	left := common.TimeUntilISO8601(e.HatchDate)
+	if left == "0" {
+		return "ready"
+	}
+	return left
+}
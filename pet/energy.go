@@ -0,0 +1,222 @@
+package pet
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+	"bcncli/market"
+	"bcncli/profile"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(energyCmd)
+
+	energyCmd.Flags().Float64("drain", 1000, "energy a pet uses per hour on an adventure (assumed flat rate)")
+	energyCmd.Flags().Int64("base-capacity", 100000, "pet energy capacity without the RaisePetEnergyCapacity perk (assumed, not from game data)")
+	energyCmd.Flags().Float64("capacity-per-level", 10, "capacity added per RaisePetEnergyCapacity level, in percent of --base-capacity (assumed)")
+	energyCmd.Flags().Float64("craving-mult", 2, "XP multiplier for feeding a pet its craving (assumed)")
+	energyCmd.Flags().Float64("craving-perk-bonus", 10, "craving multiplier added per RaisePetCravingXpMultiplier perk level, in percent")
+}
+
+// foodChoice is the food with the most energy per BC at the market.
+type foodChoice struct {
+	food  common.FoodItem
+	price int64
+}
+
+// refill returns the units and cost of the food needed for energy.
+func (f foodChoice) refill(energy int64) (int64, int64) {
+	if energy <= 0 || f.food.Energy <= 0 {
+		return 0, 0
+	}
+	units := (energy + int64(f.food.Energy) - 1) / int64(f.food.Energy)
+	return units, units * f.price
+}
+
+// costPerXP returns the BC paid per XP when the food earns mult times its
+// energy in XP.
+func (f foodChoice) costPerXP(mult float64) float64 {
+	return float64(f.price) / (float64(f.food.Energy) * mult)
+}
+
+var energyCmd = &cobra.Command{
+	Use:   "energy [userId]",
+	Short: "Estimate pet energy, refill cost and craving value",
+	Long: `Estimate when each of a player's pets runs out of energy and how much
+of the food with the most energy per BC refills it to capacity.
+
+These are estimates built on assumptions, not game data:
+  - a pet's unsynced energy is taken as its energy at the last adventure
+    sync, and it drains a flat --drain per hour on an adventure from then
+  - capacity is --base-capacity plus --capacity-per-level percent per
+    RaisePetEnergyCapacity perk level
+  - food earns XP in proportion to its energy, and a craving earns
+    --craving-mult times that, raised by the RaisePetCravingXpMultiplier perk
+
+A craving is marked worthwhile when it costs less per XP than the food with
+the most energy per BC, whatever the pet's current energy.
+
+Adventure assignments are summarized per category at the end.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		userID := common.ParseID(args[0])
+		drain, _ := cmd.Flags().GetFloat64("drain")
+		cravingMult, _ := cmd.Flags().GetFloat64("craving-mult")
+		cravingBonus, _ := cmd.Flags().GetFloat64("craving-perk-bonus")
+		baseCapacity, _ := cmd.Flags().GetInt64("base-capacity")
+		perLevel, _ := cmd.Flags().GetFloat64("capacity-per-level")
+
+		if drain <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: --drain must be positive")
+			os.Exit(1)
+		}
+
+		p := profile.Fetch(userID)
+		pets := fetchOwnedPets(userID)
+		items, err := common.LoadItemData("itemid.json", 3600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not load item data: %v\n", err)
+			os.Exit(1)
+		}
+		overview := market.FetchOverview()
+		food, ok := bestFood(items, overview)
+		if !ok {
+			fmt.Fprintln(os.Stderr, "no food has a market value")
+			os.Exit(1)
+		}
+
+		capacity := market.PetEnergyCapacity(baseCapacity, perLevel, p.Perks.RaisePetEnergyCapacity)
+		mult := cravingMult * (1 + float64(p.Perks.RaisePetCravingXpMultiplier)*cravingBonus/100)
+		now := time.Now()
+
+		fmt.Printf("%s (%d)\n", p.Name, p.ID)
+		fmt.Printf("Energy capacity %d (RaisePetEnergyCapacity level %d), craving XP %.2f×\n",
+			capacity, p.Perks.RaisePetEnergyCapacity, mult)
+		fmt.Printf("Refilling with %s (%d energy, %s, %.3f BC/XP)\n\n",
+			food.food.Name, food.food.Energy, common.FormatPrice(food.price), food.costPerXP(1))
+
+		sort.Slice(pets, func(i, j int) bool { return pets[i].ID < pets[j].ID })
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tName\tAdventure\tEnergy\tRuns Out\tRefill\tCost\tCraving\tCraving Cost\tBC/XP\tWorthwhile")
+		var totalCost int64
+		for _, pt := range pets {
+			energy, runsOut := petEnergy(pt, drain, now)
+			units, cost := food.refill(capacity - energy)
+			totalCost += cost
+
+			craving, cravingCost, perXP, worthwhile := "-", "-", "-", "-"
+			if pt.Craving.ItemID > 0 && pt.Craving.Amount > 0 {
+				name := common.LookUpItemName(int(pt.Craving.ItemID), items)
+				craving = fmt.Sprintf("%d× %s", pt.Craving.Amount, name)
+				if v, ok := overview.Price(int(pt.Craving.ItemID)); ok && v > 0 {
+					cravingCost = common.FormatPrice(v * pt.Craving.Amount)
+					if e := common.GetEnergy(name); e > 0 {
+						c := foodChoice{food: common.FoodItem{Name: name, Energy: e}, price: v}.costPerXP(mult)
+						perXP = fmt.Sprintf("%.3f", c)
+						worthwhile = "no"
+						if c <= food.costPerXP(1) {
+							worthwhile = "yes"
+						}
+					}
+				}
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%s\t%d× %s\t%s\t%s\t%s\t%s\t%s\n",
+				pt.ID, pt.Name, orDash(pt.AdventureType), energy, capacity, runsOut,
+				units, food.food.Name, common.FormatPrice(cost), craving, cravingCost, perXP, worthwhile)
+		}
+		w.Flush()
+		fmt.Printf("\nRefilling every pet costs %s\n\n", common.FormatPrice(totalCost, true))
+
+		printAdventureSummary(pets)
+	},
+}
+
+// petEnergy estimates a pet's current energy and describes when it runs
+// out. It assumes UnsyncedEnergy is the energy at LastAdventureSync and that
+// the pet drains a flat rate per hour since; pets without an adventure do
+// not drain.
+func petEnergy(p Pet, drain float64, now time.Time) (int64, string) {
+	synced, err := time.Parse(time.RFC3339, p.LastAdventureSync)
+	if p.AdventureType == "" || err != nil {
+		return p.UnsyncedEnergy, "-"
+	}
+	hours := now.Sub(synced).Hours()
+	energy := max(int64(float64(p.UnsyncedEnergy)-drain*hours), 0)
+	if energy == 0 {
+		return 0, "empty"
+	}
+	left := time.Duration(float64(energy) / drain * float64(time.Hour))
+	return energy, "in " + common.FormatDuration(left)
+}
+
+// bestFood returns the food with the most energy per BC at the market.
+func bestFood(items []common.Item, overview market.OverviewResponse) (foodChoice, bool) {
+	var best foodChoice
+	bestRatio := math.Inf(-1)
+	for _, f := range common.AllFoodItems {
+		it, err := common.FindItem(items, f.Name)
+		if err != nil {
+			continue
+		}
+		v, ok := overview.Price(it.ID)
+		if !ok || v <= 0 {
+			continue
+		}
+		if ratio := float64(f.Energy) / float64(v); ratio > bestRatio {
+			best, bestRatio = foodChoice{food: f, price: v}, ratio
+		}
+	}
+	return best, bestRatio > math.Inf(-1)
+}
+
+// printAdventureSummary summarizes adventure assignments per category.
+func printAdventureSummary(pets []Pet) {
+	type summary struct {
+		pets, natural, boosted int
+		tiers, items           int64
+	}
+	byAdventure := make(map[string]*summary)
+	for _, p := range pets {
+		adv := p.AdventureType
+		if adv == "" {
+			adv = "none"
+		}
+		s, ok := byAdventure[adv]
+		if !ok {
+			s = &summary{}
+			byAdventure[adv] = s
+		}
+		s.pets++
+		s.tiers += int64(p.Tier)
+		s.items += p.LifetimeItemsFound
+		if strings.EqualFold(common.GetPetCategory(p.Species), adv) {
+			s.natural++
+		}
+		if p.AdventureBoost.Multiplier > 1 && common.EpochToTime(p.AdventureBoost.EndTime).After(time.Now()) {
+			s.boosted++
+		}
+	}
+
+	keys := make([]string, 0, len(byAdventure))
+	for k := range byAdventure {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Adventure\tPets\tNatural\tBoosted\tAvg Tier\tItems Found")
+	for _, k := range keys {
+		s := byAdventure[k]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.1f\t%d\n",
+			k, s.pets, s.natural, s.boosted, float64(s.tiers)/float64(s.pets), s.items)
+	}
+	w.Flush()
+}
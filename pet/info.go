@@ -0,0 +1,152 @@
+package pet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/client"
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info [id]",
+	Short: "Show a pet's details",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := common.ParseID(args[0])
+
+		if debug, _ := cmd.Flags().GetBool("debug"); debug {
+			payload := map[string]interface{}{"type": "pet", "id": id}
+			common.PrintJSON(client.FetchDataOrExit(payload))
+			return
+		}
+
+		p := fetchPet(id)
+		items, err := common.LoadItemData("itemid.json", 3600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not load item data: %v\n", err)
+			os.Exit(1)
+		}
+		printPetCard(p, items)
+	},
+}
+
+// fetchPet fetches a single pet, exiting on error.
+func fetchPet(id int) Pet {
+	p, err := lookupPet(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching pet %d: %v\n", id, err)
+		os.Exit(1)
+	}
+	return p
+}
+
+// lookupPet fetches a single pet.
+func lookupPet(id int) (Pet, error) {
+	raw, err := client.FetchData(map[string]interface{}{"type": "pet", "id": id})
+	if err != nil {
+		return Pet{}, err
+	}
+	var p Pet
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return Pet{}, fmt.Errorf("parsing pet data: %w", err)
+	}
+	return p, nil
+}
+
+// printPetCard prints the details of a pet as label/value rows.
+func printPetCard(p Pet, items []common.Item) {
+	fmt.Printf("%s (%d)\n\n", p.Name, p.ID)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Species:\t%s\n", speciesLabel(p.Species))
+	fmt.Fprintf(w, "Owner:\t%d\n", p.OwnerBCID)
+	fmt.Fprintf(w, "Hatched:\t%s\n", isoLabel(p.HatchDate))
+	fmt.Fprintf(w, "Tier:\t%d\n", p.Tier)
+	fmt.Fprintf(w, "XP:\t%d\n", p.XP)
+	fmt.Fprintf(w, "Generation:\t%d\n", p.Generation)
+	fmt.Fprintf(w, "Parents:\t%s, %s\n", parentLabel(p.ParentAID), parentLabel(p.ParentBID))
+	fmt.Fprintf(w, "Times bred:\t%d\n", p.TimesBred)
+	fmt.Fprintf(w, "Last bred:\t%s\n", isoLabel(p.LastBred))
+	fmt.Fprintf(w, "Held item:\t%s\n", itemLabel(p.HeldItemID, items))
+	fmt.Fprintf(w, "Adventure:\t%s\n", orDash(p.AdventureType))
+	fmt.Fprintf(w, "Boost:\t%s\n", boostLabel(p.AdventureBoost))
+	fmt.Fprintf(w, "Last adventure sync:\t%s\n", isoLabel(p.LastAdventureSync))
+	fmt.Fprintf(w, "Unsynced energy:\t%d\n", p.UnsyncedEnergy)
+	craving := "-"
+	if p.Craving.ItemID > 0 {
+		craving = fmt.Sprintf("%d× %s", p.Craving.Amount, itemLabel(p.Craving.ItemID, items))
+	}
+	fmt.Fprintf(w, "Craving:\t%s\n", craving)
+	fmt.Fprintf(w, "Skin:\t%s\n", orDash(p.Skin))
+	fmt.Fprintf(w, "Aura:\t%s\n", orDash(p.Aura))
+	fmt.Fprintf(w, "Items found:\t%d\n", p.LifetimeItemsFound)
+	w.Flush()
+}
+
+// speciesLabel returns the species with its icon and category.
+func speciesLabel(species string) string {
+	data, ok := common.GetPetData(species)
+	if !ok {
+		return orDash(species)
+	}
+	if data.Icon == "" {
+		return fmt.Sprintf("%s (%s)", data.Name, data.Category)
+	}
+	return fmt.Sprintf("%s %s (%s)", data.Icon, data.Name, data.Category)
+}
+
+// parentLabel resolves a parent pet ID to "Name (id)". A parent that
+// cannot be fetched is reported on stderr and shown by its ID.
+func parentLabel(id int64) string {
+	if id <= 0 {
+		return "-"
+	}
+	p, err := lookupPet(int(id))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: parent %d: %v\n", id, err)
+		return fmt.Sprint(id)
+	}
+	return fmt.Sprintf("%s (%d)", p.Name, id)
+}
+
+// itemLabel resolves an item ID to "Name (id)".
+func itemLabel(id int64, items []common.Item) string {
+	if id <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%d)", common.LookUpItemName(int(id), items), id)
+}
+
+// boostLabel describes an adventure boost and how long it still runs.
+func boostLabel(b AdventureBoost) string {
+	if b.Multiplier <= 1 || b.EndTime <= 0 {
+		return "-"
+	}
+	end := common.EpochToTime(b.EndTime)
+	if !end.After(time.Now()) {
+		return fmt.Sprintf("%d× (ended %s ago)", b.Multiplier, common.FormatDuration(time.Since(end)))
+	}
+	return fmt.Sprintf("%d× (ends in %s)", b.Multiplier, common.FormatDuration(time.Until(end)))
+}
+
+// isoLabel renders an RFC 3339 timestamp with the time elapsed since.
+func isoLabel(iso string) string {
+	if _, err := time.Parse(time.RFC3339, iso); err != nil {
+		return orDash(iso)
+	}
+	return fmt.Sprintf("%s (%s ago)", iso, common.ElapsedSinceISO8601(iso))
+}
+
+// orDash returns s, or "-" when it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,252 @@
+package pet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/client"
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+)
+
+// Offspring is the result of a petOffspring lookup, split into hatched pets
+// and unhatched eggs.
+type Offspring struct {
+	Pets []Pet
+	Eggs []Egg
+}
+
+// Generation is the offspring found at one depth below a pet; depth 1 holds
+// its direct children.
+type Generation struct {
+	Depth int
+	Offspring
+}
+
+// fetchOffspring fetches the children of a pet or egg.
+func fetchOffspring(id int64) (Offspring, error) {
+	raw, err := client.FetchData(map[string]interface{}{"type": "petOffspring", "id": id})
+	if err != nil {
+		return Offspring{}, err
+	}
+	return decodeOffspring(raw, time.Now())
+}
+
+// decodeOffspring accepts a list, a wrapper with "pets" and "eggs" lists,
+// or a wrapper holding a list under "results". Entries of a list are split
+// by their hatch date: one that is still ahead of now is an egg, anything
+// else a hatched pet.
+func decodeOffspring(raw []byte, now time.Time) (Offspring, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err == nil {
+		return splitOffspring(entries, now)
+	}
+	var resp struct {
+		Pets    []Pet             `json:"pets"`
+		Eggs    []Egg             `json:"eggs"`
+		Results []json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return Offspring{}, err
+	}
+	o, err := splitOffspring(resp.Results, now)
+	if err != nil {
+		return Offspring{}, err
+	}
+	return Offspring{Pets: append(resp.Pets, o.Pets...), Eggs: append(resp.Eggs, o.Eggs...)}, nil
+}
+
+// splitOffspring decodes entries as eggs when they have not hatched yet and
+// as pets otherwise.
+func splitOffspring(entries []json.RawMessage, now time.Time) (Offspring, error) {
+	var o Offspring
+	for _, raw := range entries {
+		var e Egg
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return Offspring{}, err
+		}
+		if hatch, err := time.Parse(time.RFC3339, e.HatchDate); err == nil && hatch.After(now) {
+			o.Eggs = append(o.Eggs, e)
+			continue
+		}
+		var p Pet
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return Offspring{}, err
+		}
+		o.Pets = append(o.Pets, p)
+	}
+	return o, nil
+}
+
+// fetchDescendants walks the offspring of id through hatched pets, depth
+// generations deep, making at most rate requests per second. Failed lookups
+// are reported on stderr and counted; their branches are left out. Each pet
+// is looked up once.
+func fetchDescendants(id int64, depth int, rate float64) ([]Generation, int) {
+	var tick <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var gens []Generation
+	failed := 0
+	seen := map[int64]bool{id: true}
+	parents := []int64{id}
+	for d := 1; d <= depth && len(parents) > 0; d++ {
+		if depth > 1 {
+			fmt.Fprintf(os.Stderr, "generation %d: fetching offspring of %d pet(s)...\n", d, len(parents))
+		}
+		gen := Generation{Depth: d}
+		var next []int64
+		for _, pid := range parents {
+			if tick != nil {
+				<-tick
+			}
+			o, err := fetchOffspring(pid)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: offspring of %d: %v\n", pid, err)
+				failed++
+				continue
+			}
+			for _, p := range o.Pets {
+				if seen[p.ID] {
+					continue
+				}
+				seen[p.ID] = true
+				gen.Pets = append(gen.Pets, p)
+				next = append(next, p.ID)
+			}
+			gen.Eggs = append(gen.Eggs, o.Eggs...)
+		}
+		if len(gen.Pets)+len(gen.Eggs) > 0 {
+			gens = append(gens, gen)
+		}
+		parents = next
+	}
+	return gens, failed
+}
+
+// NewOffspringCmd returns the offspring command shared by pet and egg.
+func NewOffspringCmd(short string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "offspring [id]",
+		Short: short,
+		Long: short + `, split into hatched pets and unhatched eggs, with
+counts per skin and aura to show inheritance rates.
+
+With --recursive, hatched pets are followed down to --depth generations.
+Every generation is listed and counted on its own, so generation 1 shows the
+inheritance rates from the given parent. Failed lookups are reported and
+their branches left out, without discarding the rest.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id := common.ParseID(args[0])
+
+			if debug, _ := cmd.Flags().GetBool("debug"); debug {
+				payload := map[string]interface{}{"type": "petOffspring", "id": id}
+				common.PrintJSON(client.FetchDataOrExit(payload))
+				return
+			}
+
+			depth := 1
+			if recursive, _ := cmd.Flags().GetBool("recursive"); recursive {
+				depth, _ = cmd.Flags().GetInt("depth")
+			}
+			if depth < 1 {
+				fmt.Fprintln(os.Stderr, "Error: --depth must be at least 1")
+				os.Exit(1)
+			}
+			rate, _ := cmd.Flags().GetFloat64("rate")
+
+			gens, failed := fetchDescendants(int64(id), depth, rate)
+			if failed > 0 && len(gens) == 0 {
+				os.Exit(1)
+			}
+			if len(gens) == 0 {
+				fmt.Println("No offspring")
+			}
+			for i, g := range gens {
+				if i > 0 {
+					fmt.Println()
+				}
+				printGeneration(g)
+			}
+			if failed > 0 {
+				fmt.Fprintf(os.Stderr, "\n%d lookup(s) failed; results are partial\n", failed)
+			}
+		},
+	}
+	cmd.Flags().Bool("debug", false, "Enable debug (JSON) output")
+	cmd.Flags().BoolP("recursive", "r", false, "Follow hatched offspring down to --depth generations")
+	cmd.Flags().Int("depth", 3, "generations to follow with --recursive")
+	cmd.Flags().Float64("rate", 5, "maximum offspring requests per second (0 = unlimited)")
+	return cmd
+}
+
+// printGeneration prints the pets and eggs of a generation and their skin
+// and aura counts.
+func printGeneration(g Generation) {
+	fmt.Printf("Generation %d: %d pet(s), %d egg(s)\n", g.Depth, len(g.Pets), len(g.Eggs))
+	if len(g.Pets) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tName\tSpecies\tTier\tGen\tParents\tSkin\tAura")
+		for _, p := range g.Pets {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d, %d\t%s\t%s\n",
+				p.ID, orDash(p.Name), p.Species, p.Tier, p.Generation, p.ParentAID, p.ParentBID, orDash(p.Skin), orDash(p.Aura))
+		}
+		w.Flush()
+	}
+	if len(g.Eggs) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Egg ID\tSpecies\tTier\tGen\tParents\tHatches In\tSkin\tAura")
+		for _, e := range g.Eggs {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d, %d\t%s\t%s\t%s\n",
+				e.ID, e.Species, e.Tier, e.Generation, e.ParentAID, e.ParentBID, e.HatchesIn(), orDash(e.Skin), orDash(e.Aura))
+		}
+		w.Flush()
+	}
+
+	total := len(g.Pets) + len(g.Eggs)
+	skins, auras := make(map[string]int), make(map[string]int)
+	for _, p := range g.Pets {
+		skins[orDash(p.Skin)]++
+		auras[orDash(p.Aura)]++
+	}
+	for _, e := range g.Eggs {
+		skins[orDash(e.Skin)]++
+		auras[orDash(e.Aura)]++
+	}
+	printShare("Skin", skins, total)
+	printShare("Aura", auras, total)
+}
+
+// printShare prints counts with their share of total, most common first.
+func printShare(title string, counts map[string]int, total int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tCount\tShare\n", title)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%d\t%.1f%%\n", k, counts[k], float64(counts[k])/float64(total)*100)
+	}
+	w.Flush()
+}
@@ -0,0 +1,165 @@
+package pet
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+	"bcncli/profile"
+
+	"github.com/spf13/cobra"
+)
+
+// adventureCategories are the adventures a pet can be sent on.
+var adventureCategories = []string{"Fish", "Hunt", "Explore", "Mine"}
+
+func init() {
+	Cmd.AddCommand(optimizeCmd)
+
+	optimizeCmd.Flags().Float64("mismatch", 0.5, "yield factor of a pet on an adventure outside its category")
+	optimizeCmd.Flags().Float64("upgrade-bonus", 10, "yield added per action upgrade level, in percent")
+	optimizeCmd.Flags().Int("max-per-category", 0, "maximum pets per adventure (0 = unlimited)")
+	optimizeCmd.Flags().Bool("changes", false, "only show pets whose adventure changes")
+}
+
+// yieldModel estimates the relative adventure yield of a pet.
+type yieldModel struct {
+	mismatch     float64
+	upgradeBonus float64
+	upgrades     profile.Upgrades
+	now          time.Time
+}
+
+// yield returns the expected yield of p on adventure: its tier, times its
+// active boost, raised by the player's upgrades for the action and lowered
+// when the adventure is not the pet's own category.
+func (m yieldModel) yield(p Pet, adventure string) float64 {
+	if adventure == "" {
+		return 0
+	}
+	y := float64(p.Tier)
+	if p.AdventureBoost.Multiplier > 1 && common.EpochToTime(p.AdventureBoost.EndTime).After(m.now) {
+		y *= float64(p.AdventureBoost.Multiplier)
+	}
+	y *= 1 + float64(actionUpgrades(m.upgrades, adventure))*m.upgradeBonus/100
+	if !strings.EqualFold(common.GetPetCategory(p.Species), adventure) {
+		y *= m.mismatch
+	}
+	return y
+}
+
+// actionUpgrades returns the upgrade levels of an action.
+func actionUpgrades(u profile.Upgrades, action string) int {
+	switch strings.ToLower(action) {
+	case "fish":
+		return u.Fish + u.FishExtra
+	case "hunt":
+		return u.Hunt + u.HuntExtra
+	case "explore":
+		return u.Explore + u.ExploreExtra
+	case "mine":
+		return u.Mine + u.MineExtra
+	}
+	return 0
+}
+
+var optimizeCmd = &cobra.Command{
+	Use:   "optimize [userId]",
+	Short: "Propose adventure assignments that maximize yield",
+	Long: `Flag pets on an adventure outside their species' category and propose
+assignments that maximize expected yield. A pet's yield is its tier, times
+its active adventure boost, raised by --upgrade-bonus per upgrade level of
+the action, times --mismatch when the adventure is not its own category.
+
+Pets are assigned greedily, best yield first, respecting --max-per-category.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		userID := common.ParseID(args[0])
+		mismatch, _ := cmd.Flags().GetFloat64("mismatch")
+		bonus, _ := cmd.Flags().GetFloat64("upgrade-bonus")
+		perCategory, _ := cmd.Flags().GetInt("max-per-category")
+		onlyChanges, _ := cmd.Flags().GetBool("changes")
+
+		p := profile.Fetch(userID)
+		pets := fetchOwnedPets(userID)
+		model := yieldModel{mismatch: mismatch, upgradeBonus: bonus, upgrades: p.Upgrades, now: time.Now()}
+		proposal := assignAdventures(pets, model, perCategory)
+
+		sort.Slice(pets, func(i, j int) bool { return pets[i].ID < pets[j].ID })
+		fmt.Printf("%s (%d)\n\n", p.Name, p.ID)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tName\tSpecies\tTier\tBefore\tAfter\tYield Before\tYield After\tNote")
+		var before, after float64
+		mismatched := 0
+		for _, pt := range pets {
+			yb, ya := model.yield(pt, pt.AdventureType), model.yield(pt, proposal[pt.ID])
+			before += yb
+			after += ya
+			category := common.GetPetCategory(pt.Species)
+			note := ""
+			if pt.AdventureType != "" && category != "" && !strings.EqualFold(category, pt.AdventureType) {
+				note = "mismatched"
+				mismatched++
+			}
+			if !strings.EqualFold(pt.AdventureType, proposal[pt.ID]) {
+				note = strings.TrimPrefix(note+", move", ", ")
+			} else if onlyChanges {
+				continue
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\t%.1f\t%.1f\t%s\n",
+				pt.ID, pt.Name, speciesLabel(pt.Species), pt.Tier,
+				orDash(pt.AdventureType), orDash(proposal[pt.ID]), yb, ya, note)
+		}
+		w.Flush()
+
+		fmt.Printf("\n%d pet(s) on a mismatched adventure\n", mismatched)
+		if before > 0 {
+			fmt.Printf("Expected yield: %.1f -> %.1f (%+.1f%%)\n", before, after, (after-before)/before*100)
+		} else {
+			fmt.Printf("Expected yield: %.1f -> %.1f\n", before, after)
+		}
+	},
+}
+
+// assignAdventures picks an adventure for every pet, taking the highest
+// yielding pet and adventure combinations first. perCategory limits the pets
+// per adventure; 0 means unlimited.
+func assignAdventures(pets []Pet, m yieldModel, perCategory int) map[int64]string {
+	type option struct {
+		pet       int64
+		adventure string
+		yield     float64
+		current   bool
+	}
+	var options []option
+	for _, p := range pets {
+		for _, adv := range adventureCategories {
+			options = append(options, option{p.ID, adv, m.yield(p, adv), strings.EqualFold(p.AdventureType, adv)})
+		}
+	}
+	// on equal yield, keep pets where they are
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].yield != options[j].yield {
+			return options[i].yield > options[j].yield
+		}
+		return options[i].current && !options[j].current
+	})
+
+	assigned := make(map[int64]string, len(pets))
+	used := make(map[string]int)
+	for _, o := range options {
+		if _, done := assigned[o.pet]; done {
+			continue
+		}
+		if perCategory > 0 && used[o.adventure] >= perCategory {
+			continue
+		}
+		assigned[o.pet] = o.adventure
+		used[o.adventure]++
+	}
+	return assigned
+}
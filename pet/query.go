@@ -0,0 +1,198 @@
+package pet
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"bcncli/query"
+)
+
+// Tables of pets (and eggs) are driven by a list of named fields, sorted and
+// filtered by the shared query engine. The same names are used by every
+// flag:
+//
+//	--sort    field[:asc|desc][,field...]
+//	--where   field<op>value[ && field<op>value...]   with op one of = != > >= < <=
+//	--columns field[,field...]
+//	--group   field[,field...]
+
+// Field is a named column of a table of T. Value returns a number or a
+// string and is used for sorting, filtering and grouping; Format, when set,
+// renders the cell instead of Value.
+type Field[T any] struct {
+	Name   string
+	Header string
+	Value  func(T) any
+	Format func(T) string
+}
+
+// Cell renders the field of row.
+func (f Field[T]) Cell(row T) string {
+	if f.Format != nil {
+		return f.Format(row)
+	}
+	return fmt.Sprint(f.Value(row))
+}
+
+// Fields is an ordered set of table fields.
+type Fields[T any] []Field[T]
+
+// Lookup finds a field by name (case-insensitive).
+func (fs Fields[T]) Lookup(name string) (Field[T], error) {
+	name = strings.TrimSpace(name)
+	for _, f := range fs {
+		if strings.EqualFold(f.Name, name) {
+			return f, nil
+		}
+	}
+	return Field[T]{}, fmt.Errorf("unknown field %q (valid: %s)", name, strings.Join(fs.Names(), ", "))
+}
+
+// Names returns the names of all fields.
+func (fs Fields[T]) Names() []string {
+	names := make([]string, len(fs))
+	for i, f := range fs {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Select returns the fields named in a comma-separated list.
+func (fs Fields[T]) Select(spec string) (Fields[T], error) {
+	var out Fields[T]
+	for _, name := range strings.Split(spec, ",") {
+		f, err := fs.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// SortRows sorts rows by a comma-separated list of field[:asc|desc] terms.
+func SortRows[T any](rows []T, fs Fields[T], spec string) error {
+	var terms []query.Term[T]
+	for _, raw := range strings.Split(spec, ",") {
+		name, dir, _ := strings.Cut(strings.TrimSpace(raw), ":")
+		f, err := fs.Lookup(name)
+		if err != nil {
+			return err
+		}
+		desc, err := query.Direction(dir)
+		if err != nil {
+			return err
+		}
+		terms = append(terms, query.Term[T]{Value: f.Value, Desc: desc})
+	}
+	query.Sort(rows, terms)
+	return nil
+}
+
+// FilterRows keeps the rows matching every expression. Each expression may
+// join several comparisons with &&. Values that cannot be compared with
+// their field, such as tier>=abc, are an error.
+func FilterRows[T any](rows []T, fs Fields[T], exprs []string) ([]T, error) {
+	var conds []query.Condition[T]
+	for _, expr := range exprs {
+		for _, part := range strings.Split(expr, "&&") {
+			c, err := parseCondition(fs, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, c)
+		}
+	}
+	return query.Filter(rows, conds), nil
+}
+
+// parseCondition parses "field<op>value". Values may be quoted.
+func parseCondition[T any](fs Fields[T], expr string) (query.Condition[T], error) {
+	name, op, value, err := query.Split(expr)
+	if err != nil {
+		return query.Condition[T]{}, err
+	}
+	f, err := fs.Lookup(name)
+	if err != nil {
+		return query.Condition[T]{}, err
+	}
+	// a field's kind is that of its value on an empty row
+	var zero T
+	lit, err := query.Literal(f.Name, query.KindOf(reflect.TypeOf(f.Value(zero))), value)
+	if err != nil {
+		return query.Condition[T]{}, err
+	}
+	return query.Condition[T]{Value: f.Value, Op: op, Literal: lit}, nil
+}
+
+// Group is a set of rows sharing the values of the group fields.
+type Group[T any] struct {
+	Key  string
+	Rows []T
+}
+
+// GroupRows groups rows by a comma-separated list of fields. Groups are
+// ordered by their field values; rows keep their order.
+func GroupRows[T any](rows []T, fs Fields[T], spec string) ([]Group[T], error) {
+	keys, err := fs.Select(spec)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	var groups []Group[T]
+	var firsts []T
+	for _, r := range rows {
+		parts := make([]string, len(keys))
+		for i, f := range keys {
+			parts[i] = f.Cell(r)
+		}
+		key := strings.Join(parts, " / ")
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group[T]{Key: key})
+			firsts = append(firsts, r)
+		}
+		groups[i].Rows = append(groups[i].Rows, r)
+	}
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		for _, f := range keys {
+			if c := query.Compare(f.Value(firsts[order[i]]), f.Value(firsts[order[j]])); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	sorted := make([]Group[T], len(groups))
+	for i, o := range order {
+		sorted[i] = groups[o]
+	}
+	return sorted, nil
+}
+
+// PrintRows writes rows as a table with the given columns.
+func PrintRows[T any](out io.Writer, rows []T, cols Fields[T]) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(cols))
+	for i, f := range cols {
+		headers[i] = f.Header
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, r := range rows {
+		cells := make([]string, len(cols))
+		for i, f := range cols {
+			cells[i] = f.Cell(r)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+}
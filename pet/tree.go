@@ -0,0 +1,276 @@
+package pet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(treeCmd)
+
+	treeCmd.Flags().Int("up", 2, "generations of ancestors to show")
+	treeCmd.Flags().Int("down", 2, "generations of descendants to show")
+	treeCmd.Flags().StringP("format", "f", "ascii", "output format: ascii, dot, or mermaid")
+}
+
+// lineage memoizes pet and offspring lookups while walking a family tree.
+// Failed lookups are reported on stderr and remembered too: a pet that
+// cannot be fetched becomes a node labelled with its ID and no relatives.
+type lineage struct {
+	pets     map[int64]Pet
+	children map[int64][]int64
+	missing  map[int64]bool
+}
+
+func newLineage() *lineage {
+	return &lineage{pets: make(map[int64]Pet), children: make(map[int64][]int64), missing: make(map[int64]bool)}
+}
+
+// pet returns a pet, fetching it on first use.
+func (l *lineage) pet(id int64) Pet {
+	p, ok := l.pets[id]
+	if !ok {
+		var err error
+		if p, err = lookupPet(int(id)); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: pet %d: %v\n", id, err)
+			p = Pet{ID: id}
+			l.missing[id] = true
+		}
+		l.pets[id] = p
+	}
+	return p
+}
+
+// label describes a pet in one line, or by its ID alone when it could not
+// be fetched.
+func (l *lineage) label(id int64) string {
+	p := l.pet(id)
+	if l.missing[id] {
+		return fmt.Sprint(id)
+	}
+	return treeLabel(p)
+}
+
+// offspring returns the IDs of a pet's hatched children, fetching them on
+// first use. Children are remembered, so they are not fetched again.
+func (l *lineage) offspring(id int64) []int64 {
+	ids, ok := l.children[id]
+	if ok {
+		return ids
+	}
+	o, err := fetchOffspring(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: offspring of %d: %v\n", id, err)
+	}
+	for _, c := range o.Pets {
+		l.pets[c.ID] = c
+		ids = append(ids, c.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	l.children[id] = ids
+	return ids
+}
+
+// parents returns the known parent IDs of a pet.
+func (l *lineage) parents(id int64) []int64 {
+	p := l.pet(id)
+	var ids []int64
+	for _, pid := range []int64{p.ParentAID, p.ParentBID} {
+		if pid > 0 {
+			ids = append(ids, pid)
+		}
+	}
+	return ids
+}
+
+// decodePetList decodes a response that is either a list of pets or a
+// wrapper object holding one under "pets" or "results".
+func decodePetList(raw []byte) ([]Pet, error) {
+	var pets []Pet
+	if err := json.Unmarshal(raw, &pets); err == nil {
+		return pets, nil
+	}
+	var resp struct {
+		Pets    []Pet `json:"pets"`
+		Results []Pet `json:"results"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+	return append(resp.Pets, resp.Results...), nil
+}
+
+var treeCmd = &cobra.Command{
+	Use:   "tree [id]",
+	Short: "Show a pet's family tree",
+	Long: `Walk a pet's ancestors (--up) through pet lookups and its descendants
+(--down) through offspring lookups, and print the family tree as ASCII,
+Graphviz DOT or Mermaid. Every pet shows its tier, generation, skin and
+aura, to trace inheritance across generations. A pet that cannot be
+fetched is reported and shown by its ID, and the rest of the tree is kept.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := int64(common.ParseID(args[0]))
+		up, _ := cmd.Flags().GetInt("up")
+		down, _ := cmd.Flags().GetInt("down")
+		format, _ := cmd.Flags().GetString("format")
+
+		var render func(l *lineage, root int64, up, down int)
+		switch strings.ToLower(format) {
+		case "ascii":
+			render = printASCIITree
+		case "dot":
+			render = printDOTTree
+		case "mermaid":
+			render = printMermaidTree
+		default:
+			fmt.Fprintf(os.Stderr, "Invalid format: %s (must be ascii, dot, or mermaid)\n", format)
+			os.Exit(1)
+		}
+		l := newLineage()
+		l.pet(id)
+		if l.missing[id] {
+			os.Exit(1)
+		}
+		render(l, id, up, down)
+	},
+}
+
+// treeLabel describes a pet in one line.
+func treeLabel(p Pet) string {
+	label := fmt.Sprintf("%s (%d) %s T%d gen %d", p.Name, p.ID, p.Species, p.Tier, p.Generation)
+	if p.Skin != "" {
+		label += " skin:" + p.Skin
+	}
+	if p.Aura != "" {
+		label += " aura:" + p.Aura
+	}
+	return label
+}
+
+// printASCIITree prints the ancestors and descendants of root as two
+// indented trees.
+func printASCIITree(l *lineage, root int64, up, down int) {
+	fmt.Println(l.label(root))
+	if up > 0 {
+		fmt.Println("\nAncestors")
+		printBranch(l, root, "", up, l.parents)
+	}
+	if down > 0 {
+		fmt.Println("\nDescendants")
+		printBranch(l, root, "", down, l.offspring)
+	}
+}
+
+// printBranch prints the relatives of id returned by next, depth levels deep.
+func printBranch(l *lineage, id int64, prefix string, depth int, next func(int64) []int64) {
+	if depth == 0 {
+		return
+	}
+	ids := next(id)
+	for i, rel := range ids {
+		branch, indent := "├── ", "│   "
+		if i == len(ids)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Println(prefix + branch + l.label(rel))
+		printBranch(l, rel, prefix+indent, depth-1, next)
+	}
+}
+
+// treeEdges collects parent-to-child edges around root, sorted.
+func treeEdges(l *lineage, root int64, up, down int) [][2]int64 {
+	seen := make(map[[2]int64]bool)
+	var edges [][2]int64
+	add := func(parent, child int64) {
+		e := [2]int64{parent, child}
+		if !seen[e] {
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+	var walkUp func(id int64, depth int)
+	walkUp = func(id int64, depth int) {
+		if depth == 0 {
+			return
+		}
+		for _, pid := range l.parents(id) {
+			add(pid, id)
+			walkUp(pid, depth-1)
+		}
+	}
+	var walkDown func(id int64, depth int)
+	walkDown = func(id int64, depth int) {
+		if depth == 0 {
+			return
+		}
+		for _, cid := range l.offspring(id) {
+			add(id, cid)
+			walkDown(cid, depth-1)
+		}
+	}
+	walkUp(root, up)
+	walkDown(root, down)
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+	return edges
+}
+
+// treeNodes returns the IDs of root and every pet in edges, sorted.
+func treeNodes(root int64, edges [][2]int64) []int64 {
+	seen := map[int64]bool{root: true}
+	ids := []int64{root}
+	for _, e := range edges {
+		for _, id := range e {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// printDOTTree prints the family tree as a Graphviz digraph.
+func printDOTTree(l *lineage, root int64, up, down int) {
+	edges := treeEdges(l, root, up, down)
+	fmt.Println("digraph pets {")
+	fmt.Println("  node [shape=box];")
+	for _, id := range treeNodes(root, edges) {
+		attrs := ""
+		if id == root {
+			attrs = ", style=bold"
+		}
+		fmt.Printf("  p%d [label=%q%s];\n", id, l.label(id), attrs)
+	}
+	for _, e := range edges {
+		fmt.Printf("  p%d -> p%d;\n", e[0], e[1])
+	}
+	fmt.Println("}")
+}
+
+// printMermaidTree prints the family tree as a Mermaid flowchart.
+func printMermaidTree(l *lineage, root int64, up, down int) {
+	edges := treeEdges(l, root, up, down)
+	fmt.Println("graph TD")
+	for _, id := range treeNodes(root, edges) {
+		label := strings.ReplaceAll(l.label(id), `"`, "#quot;")
+		fmt.Printf("  p%d[\"%s\"]\n", id, label)
+	}
+	for _, e := range edges {
+		fmt.Printf("  p%d --> p%d\n", e[0], e[1])
+	}
+	fmt.Printf("  style p%d stroke-width:3px\n", root)
+}
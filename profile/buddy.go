@@ -0,0 +1,168 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+	"bcncli/faction"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultBossAttackCooldown is the assumed wait between two buddy boss
+// attacks; the API does not report it.
+const defaultBossAttackCooldown = 24 * time.Hour
+
+func init() {
+	Cmd.AddCommand(buddyCmd)
+
+	buddyCmd.Flags().Bool("pairs", false, "treat the ID as a faction ID and list every buddy pair among its members")
+	buddyCmd.Flags().Duration("cooldown", defaultBossAttackCooldown, "buddy boss attack cooldown (assumed, not from game data)")
+}
+
+var buddyCmd = &cobra.Command{
+	Use:   "buddy [id]",
+	Short: "Show a player's buddy and boss attack cooldowns",
+	Long: `Show a player's buddy with both players' buddy boss attack cooldowns,
+when each can attack next, and whether they share a faction.
+
+With --pairs the ID is a faction ID, and every buddy pair among the
+faction's members is listed. Members whose profile cannot be fetched are
+reported and listed as unresolved.
+
+The next attack assumes a --cooldown of 24h, which is not from game data.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := common.ParseID(args[0])
+		cooldown, _ := cmd.Flags().GetDuration("cooldown")
+
+		if pairs, _ := cmd.Flags().GetBool("pairs"); pairs {
+			printBuddyPairs(id)
+			return
+		}
+
+		p := decodeProfile("profile", id)
+		if p.BuddyID == 0 {
+			fmt.Printf("%s (%d) has no buddy\n", p.Name, p.ID)
+			return
+		}
+		buddy := decodeProfile("profile", int(p.BuddyID))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PLAYER\tFACTION\tBUDDY SET\tLAST ATTACK\tNEXT ATTACK")
+		for _, pl := range []ProfileInfo{p, buddy} {
+			fmt.Fprintf(w, "%s (%d)\t%s\t%s\t%s\t%s\n",
+				pl.Name, pl.ID,
+				factionLabel(pl),
+				sinceLabel(pl.Cooldowns.SetBuddy),
+				sinceLabel(pl.Cooldowns.BuddyBossAttack),
+				nextAttack(pl.Cooldowns.BuddyBossAttack, cooldown))
+		}
+		w.Flush()
+
+		fmt.Println()
+		fmt.Printf("Same faction: %t\n", p.FactionID != 0 && p.FactionID == buddy.FactionID)
+		if buddy.BuddyID != int64(p.ID) {
+			fmt.Printf("Note: %s's buddy is %d, not %s\n", buddy.Name, buddy.BuddyID, p.Name)
+		}
+	},
+}
+
+// printBuddyPairs lists the buddy pairs among a faction's members. Members
+// whose profile cannot be fetched are listed as unresolved.
+func printBuddyPairs(factionID int) {
+	members := faction.FetchMembers(factionID)
+
+	names := make(map[int64]string, len(members))
+	profiles := make(map[int64]ProfileInfo, len(members))
+	var unresolved []string
+	for _, m := range members {
+		names[m.BcID] = m.Name
+		p, err := Lookup(int(m.BcID))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: profile %d: %v\n", m.BcID, err)
+			unresolved = append(unresolved, fmt.Sprintf("%s (%d)", m.Name, m.BcID))
+			continue
+		}
+		profiles[m.BcID] = p
+	}
+
+	ids := make([]int64, 0, len(profiles))
+	for id := range profiles {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLAYER\tBUDDY\tMUTUAL\tIN FACTION")
+	var solo []string
+	for _, id := range ids {
+		p := profiles[id]
+		if p.BuddyID == 0 {
+			solo = append(solo, fmt.Sprintf("%s (%d)", p.Name, p.ID))
+			continue
+		}
+		_, inFaction := names[p.BuddyID]
+		buddy, resolved := profiles[p.BuddyID]
+		isMutual := resolved && buddy.BuddyID == id
+		// print mutual pairs once, from the lower ID
+		if isMutual && p.BuddyID < id {
+			continue
+		}
+		buddyName := fmt.Sprint(p.BuddyID)
+		if inFaction {
+			buddyName = fmt.Sprintf("%s (%d)", names[p.BuddyID], p.BuddyID)
+		}
+		mutual := fmt.Sprint(isMutual)
+		if inFaction && !resolved {
+			mutual = "?"
+		}
+		fmt.Fprintf(w, "%s (%d)\t%s\t%s\t%t\n", p.Name, p.ID, buddyName, mutual, inFaction)
+	}
+	w.Flush()
+
+	if len(solo) > 0 {
+		fmt.Printf("\nWithout buddy (%d):\n", len(solo))
+		for _, s := range solo {
+			fmt.Println("  " + s)
+		}
+	}
+	if len(unresolved) > 0 {
+		fmt.Printf("\nUnresolved (%d):\n", len(unresolved))
+		for _, s := range unresolved {
+			fmt.Println("  " + s)
+		}
+	}
+}
+
+// factionLabel returns the faction tag of a player, or "-".
+func factionLabel(p ProfileInfo) string {
+	if p.FactionID == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%d)", p.FactionTag, p.FactionID)
+}
+
+// sinceLabel renders an epoch-millisecond timestamp as time elapsed.
+func sinceLabel(ms int64) string {
+	if ms <= 0 {
+		return "never"
+	}
+	return common.ElapsedSinceISO8601(common.EpochToISO8601(ms)) + " ago"
+}
+
+// nextAttack returns when the next boss attack is possible after lastMs.
+func nextAttack(lastMs int64, cooldown time.Duration) string {
+	if lastMs <= 0 {
+		return "ready"
+	}
+	next := common.EpochToTime(lastMs).Add(cooldown)
+	if !next.After(time.Now()) {
+		return "ready"
+	}
+	return fmt.Sprintf("in %s (%s)", common.FormatDuration(time.Until(next)), next.Format(time.RFC3339))
+}
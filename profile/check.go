@@ -0,0 +1,214 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"bcncli/common"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().Int("premium-days", 7, "fail when premium expires within this many days")
+	checkCmd.Flags().Duration("streak-window", 48*time.Hour, "time after the last claim or vote at which a streak breaks")
+	checkCmd.Flags().Duration("streak-margin", 6*time.Hour, "fail when a streak breaks within this duration")
+	checkCmd.Flags().BoolP("quiet", "q", false, "only print failed checks")
+}
+
+// checkStatus is the outcome of a single health check.
+type checkStatus string
+
+const (
+	checkOK   checkStatus = "OK"
+	checkFail checkStatus = "FAIL"
+	checkInfo checkStatus = "INFO"
+)
+
+// checkResult is one line of the health report.
+type checkResult struct {
+	name   string
+	status checkStatus
+	detail string
+}
+
+// checkOptions holds the thresholds of the health rules.
+type checkOptions struct {
+	premiumWithin time.Duration
+	streakWindow  time.Duration
+	streakMargin  time.Duration
+	items         []common.Item
+}
+
+// healthRule inspects a profile and reports zero or more results.
+type healthRule func(p ProfileInfo, opts checkOptions) []checkResult
+
+// healthRules are run in order for every checked profile.
+var healthRules = []healthRule{
+	checkBan,
+	checkPremium,
+	checkDailyStreak,
+	checkVoteStreak,
+	checkReserves,
+	checkCaptcha,
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check [id] [...]",
+	Short: "Run account health checks",
+	Long: `Run account health checks for one or more players: active bans, premium
+expiring soon, daily claim and vote streaks about to break, and item
+reserves that are full.
+
+The command exits with status 1 when any check fails, so it can be used
+from cron.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		days, _ := cmd.Flags().GetInt("premium-days")
+		window, _ := cmd.Flags().GetDuration("streak-window")
+		margin, _ := cmd.Flags().GetDuration("streak-margin")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+
+		items, err := common.LoadItemData("itemid.json", 3600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not load item data: %v\n", err)
+			os.Exit(1)
+		}
+		opts := checkOptions{
+			premiumWithin: time.Duration(days) * 24 * time.Hour,
+			streakWindow:  window,
+			streakMargin:  margin,
+			items:         items,
+		}
+
+		failed, printed := 0, 0
+		for _, arg := range args {
+			p := decodeProfile("profile", common.ParseID(arg))
+
+			var results []checkResult
+			for _, rule := range healthRules {
+				for _, r := range rule(p, opts) {
+					if r.status == checkFail {
+						failed++
+					}
+					if !quiet || r.status == checkFail {
+						results = append(results, r)
+					}
+				}
+			}
+			if quiet && len(results) == 0 {
+				continue
+			}
+
+			if printed > 0 {
+				fmt.Println()
+			}
+			printed++
+			fmt.Printf("%s (%d)\n", p.Name, p.ID)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+			for _, r := range results {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", r.name, r.status, r.detail)
+			}
+			w.Flush()
+		}
+
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "%d check(s) failed\n", failed)
+			os.Exit(1)
+		}
+	},
+}
+
+// checkBan fails while a ban is active.
+func checkBan(p ProfileInfo, _ checkOptions) []checkResult {
+	t, err := time.Parse(time.RFC3339, p.BanExpiryDate)
+	if err != nil || !t.After(time.Now()) {
+		return []checkResult{{"ban", checkOK, "not banned"}}
+	}
+	reason := "no reason given"
+	if p.BanReason != nil && *p.BanReason != "" {
+		reason = *p.BanReason
+	}
+	return []checkResult{{"ban", checkFail, fmt.Sprintf("banned for %s (%s)", common.FormatDuration(time.Until(t)), reason)}}
+}
+
+// checkPremium fails when premium expires within the configured window.
+func checkPremium(p ProfileInfo, opts checkOptions) []checkResult {
+	if p.PremiumExpiryDate == nil || *p.PremiumExpiryDate == "" {
+		return []checkResult{{"premium", checkInfo, "no premium"}}
+	}
+	t, err := time.Parse(time.RFC3339, *p.PremiumExpiryDate)
+	if err != nil {
+		return []checkResult{{"premium", checkInfo, "unreadable expiry date " + *p.PremiumExpiryDate}}
+	}
+	left := time.Until(t)
+	switch {
+	case left <= 0:
+		return []checkResult{{"premium", checkFail, fmt.Sprintf("expired %s ago", common.FormatDuration(left))}}
+	case left <= opts.premiumWithin:
+		return []checkResult{{"premium", checkFail, fmt.Sprintf("expires in %s", common.FormatDuration(left))}}
+	}
+	return []checkResult{{"premium", checkOK, fmt.Sprintf("expires in %s", common.FormatDuration(left))}}
+}
+
+// checkDailyStreak fails when the daily claim streak is about to break.
+func checkDailyStreak(p ProfileInfo, opts checkOptions) []checkResult {
+	return []checkResult{streakResult("daily streak", p.DailyClaimStreak, p.Cooldowns.Daily, opts)}
+}
+
+// checkVoteStreak fails when the top.gg vote streak is about to break.
+func checkVoteStreak(p ProfileInfo, opts checkOptions) []checkResult {
+	return []checkResult{streakResult("vote streak", p.DailyVoteStreak, p.Cooldowns.TopGgVote, opts)}
+}
+
+// streakResult checks a streak against the time of its last claim.
+func streakResult(name string, streak int, lastMs int64, opts checkOptions) checkResult {
+	if streak <= 0 || lastMs <= 0 {
+		return checkResult{name, checkInfo, "no active streak"}
+	}
+	left := time.Until(common.EpochToTime(lastMs).Add(opts.streakWindow))
+	switch {
+	case left <= 0:
+		return checkResult{name, checkFail, fmt.Sprintf("streak of %d broke %s ago", streak, common.FormatDuration(left))}
+	case left <= opts.streakMargin:
+		return checkResult{name, checkFail, fmt.Sprintf("streak of %d breaks in %s", streak, common.FormatDuration(left))}
+	}
+	return checkResult{name, checkOK, fmt.Sprintf("streak of %d, breaks in %s", streak, common.FormatDuration(left))}
+}
+
+// checkReserves fails for every item whose held amount has reached its
+// reserve amount. Inventory is indexed by item ID.
+func checkReserves(p ProfileInfo, opts checkOptions) []checkResult {
+	var results []checkResult
+	for _, k := range sortedKeys(reflect.ValueOf(p.ItemReserveAmounts)) {
+		reserve := p.ItemReserveAmounts[k.String()]
+		id, err := strconv.Atoi(k.String())
+		if err != nil || reserve <= 0 || id < 0 || id >= len(p.Inventory) {
+			continue
+		}
+		if held := p.Inventory[id]; held >= reserve {
+			name := common.LookUpItemName(id, opts.items)
+			results = append(results, checkResult{"reserve", checkFail, fmt.Sprintf("%s full: %d of %d", name, held, reserve)})
+		}
+	}
+	if len(results) == 0 {
+		return []checkResult{{"reserve", checkOK, fmt.Sprintf("%d reserve(s) below limit", len(p.ItemReserveAmounts))}}
+	}
+	return results
+}
+
+// checkCaptcha reports when the last captcha was solved.
+func checkCaptcha(p ProfileInfo, _ checkOptions) []checkResult {
+	t, err := time.Parse(time.RFC3339, p.LastCaptchaDate)
+	if err != nil {
+		return []checkResult{{"captcha", checkInfo, "never"}}
+	}
+	return []checkResult{{"captcha", checkInfo, fmt.Sprintf("last solved %s ago", common.FormatDuration(time.Since(t)))}}
+}
@@ -6,10 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"sort"
-	"strconv"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 )
@@ -24,8 +21,9 @@ func init() {
 
 	for _, c := range []*cobra.Command{infoCmd, userCmd} {
 		c.Flags().BoolP("debug", "d", false, "print raw JSON response")
-		c.Flags().StringP("filter", "f", "", "comma-separated list of sections to print (e.g. farms,pets)")
-		c.Flags().StringP("sort", "s", "", "sorting key for a section (e.g. farm:plant)")
+		c.Flags().StringP("filter", "f", "", "comma-separated list of sections to print (e.g. farms,quests)")
+		c.Flags().StringP("sort", "s", "", "comma-separated section:key[:asc|desc] terms (e.g. quests:missing:desc)")
+		c.Flags().StringArrayP("where", "w", nil, "keep list entries matching section.key<op>value, repeatable (e.g. farms.isPlanted=true)")
 	}
 }
 
@@ -33,7 +31,7 @@ func init() {
 type ProfileInfo struct {
 	ID                    int               `json:"id"`
 	Name                  string            `json:"name"`
-	RegistrationDate      string            `json:"registrationDate"`
+	RegistrationDate      string            `json:"registrationDate" view:"since"`
 	Rank                  int               `json:"rank"`
 	Tier                  int               `json:"tier"`
 	BC                    int64             `json:"bc"`
@@ -42,7 +40,7 @@ type ProfileInfo struct {
 	BuddyID               int64             `json:"buddyId"`
 	FactionID             int64             `json:"factionId"`
 	FactionTag            string            `json:"factionTag"`
-	FactionJoinDate       string            `json:"factionJoinDate"`
+	FactionJoinDate       string            `json:"factionJoinDate" view:"since"`
 	FactionDepositWeekly  int64             `json:"factionDepositWeekly"`
 	FactionDepositTotal   int64             `json:"factionDepositTotal"`
 	QuestLevel            int               `json:"questLevel"`
@@ -50,33 +48,33 @@ type ProfileInfo struct {
 	DailyClaimStreak      int               `json:"dailyClaimStreak"`
 	DailyVoteStreak       int               `json:"dailyVoteStreak"`
 	PetsBredDaily         int               `json:"petsBredDaily"`
-	LastCaptchaDate       string            `json:"lastCaptchaDate"`
-	FarmPlots             []FarmPlot        `json:"farmPlots"`
+	LastCaptchaDate       string            `json:"lastCaptchaDate" view:"since"`
+	FarmPlots             []FarmPlot        `json:"farmPlots" section:"farms,farm"`
 	Generators            []Generator       `json:"generators"`
 	Quests                []Quest           `json:"quests"`
 	Cooldowns             Cooldowns         `json:"cooldowns"`
 	Effects               map[string]Effect `json:"effects"`
 	Upgrades              Upgrades          `json:"upgrades"`
-	EquippedFlatInventory map[string]any    `json:"equippedFlatInventory"`
+	EquippedFlatInventory map[string]any    `json:"equippedFlatInventory" section:"equipped" view:"item"`
 	Perks                 Perks             `json:"perks"`
-	PinnedItemIDs         []int64           `json:"pinnedItemIds"`
-	PinnedPetIDs          []int64           `json:"pinnedPetIds"`
-	AutosellLimits        map[string]int64  `json:"autosellLimits"`
-	ItemReserveAmounts    map[string]int64  `json:"itemReserveAmounts"`
+	PinnedItemIDs         []int64           `json:"pinnedItemIds" section:"pinneditems" view:"item"`
+	PinnedPetIDs          []int64           `json:"pinnedPetIds" section:"pinnedpets" view:"pet"`
+	AutosellLimits        map[string]int64  `json:"autosellLimits" section:"autosell" view:"item"`
+	ItemReserveAmounts    map[string]int64  `json:"itemReserveAmounts" section:"reserves" view:"item"`
 	Settings              Settings          `json:"settings"`
 	Custom                Custom            `json:"custom"`
-	DiscordServerIDs      []string          `json:"discordServerIds"`
-	BlockedBcIDs          []int64           `json:"blockedBcIds"`
-	BanExpiryDate         string            `json:"banExpiryDate"`
+	DiscordServerIDs      []string          `json:"discordServerIds" section:"discordservers"`
+	BlockedBcIDs          []int64           `json:"blockedBcIds" section:"blocked"`
+	BanExpiryDate         string            `json:"banExpiryDate" view:"until"`
 	BanReason             *string           `json:"banReason"`
-	PremiumExpiryDate     *string           `json:"premiumExpiryDate"`
+	PremiumExpiryDate     *string           `json:"premiumExpiryDate" view:"until"`
 	DiscordID             *string           `json:"discordId"`
 	DiscordAvatarHash     *string           `json:"discordAvatarHash"`
 	DiscordUsername       *string           `json:"discordUsername"`
 	IsModerator           bool              `json:"isModerator"`
-	Inventory             []int64           `json:"inventory"`
+	Inventory             []int64           `json:"inventory" view:"stock"` // amount held, indexed by item ID
 	Faction               Faction           `json:"faction"`
-	LbPositions           LbPositions       `json:"lbPositions"`
+	LbPositions           LbPositions       `json:"lbPositions" section:"leaderboard"`
 }
 
 // FarmPlot represents a single farm plot in the user's profile.
@@ -90,14 +88,14 @@ type FarmPlot struct {
 // PlantStatus represents the planting status of a farm plot.
 type PlantStatus struct {
 	IsPlanted   bool  `json:"isPlanted"`
-	ItemID      int   `json:"itemId"`
-	PlantedTime int64 `json:"plantedTime"`
+	ItemID      int   `json:"itemId" view:"item" key:"plant,item"`
+	PlantedTime int64 `json:"plantedTime" view:"since"`
 }
 
 // Boost represents a farm plot boost with multiplier and end time.
 type Boost struct {
-	Multiplier int   `json:"multiplier"`
-	EndTime    int64 `json:"endTime"`
+	Multiplier int   `json:"multiplier" key:"mult"`
+	EndTime    int64 `json:"endTime" view:"until"`
 }
 
 // Generator represents a generator in the user's profile.
@@ -108,30 +106,38 @@ type Generator struct {
 
 // Quest represents a quest in the user's profile.
 type Quest struct {
-	ItemID          int   `json:"itemId"`
+	ItemID          int   `json:"itemId" view:"item"`
 	AmountRequired  int64 `json:"amountRequired"`
 	AmountFulfilled int64 `json:"amountFulfilled"`
 }
 
+// Missing returns how many items are still needed to complete the quest.
+func (q Quest) Missing() int64 {
+	if q.AmountFulfilled >= q.AmountRequired {
+		return 0
+	}
+	return q.AmountRequired - q.AmountFulfilled
+}
+
 // Cooldowns represents the various action cooldowns in the user's profile.
 type Cooldowns struct {
-	Fish            int64 `json:"fish"`
-	Hunt            int64 `json:"hunt"`
-	Explore         int64 `json:"explore"`
-	Mine            int64 `json:"mine"`
-	Work            int64 `json:"work"`
-	Daily           int64 `json:"daily"`
-	Water           int64 `json:"water"`
-	ClaimGenerators int64 `json:"claimGenerators"`
-	SetBuddy        int64 `json:"setBuddy"`
-	BuddyBossAttack int64 `json:"buddyBossAttack"`
-	TopGgVote       int64 `json:"topGgVote"`
-	Item38Use       int64 `json:"item38Use"`
+	Fish            int64 `json:"fish" view:"since"`
+	Hunt            int64 `json:"hunt" view:"since"`
+	Explore         int64 `json:"explore" view:"since"`
+	Mine            int64 `json:"mine" view:"since"`
+	Work            int64 `json:"work" view:"since"`
+	Daily           int64 `json:"daily" view:"since"`
+	Water           int64 `json:"water" view:"since"`
+	ClaimGenerators int64 `json:"claimGenerators" view:"since"`
+	SetBuddy        int64 `json:"setBuddy" view:"since"`
+	BuddyBossAttack int64 `json:"buddyBossAttack" view:"since"`
+	TopGgVote       int64 `json:"topGgVote" view:"since"`
+	Item38Use       int64 `json:"item38Use" view:"since"`
 }
 
 // Effect represents a temporary effect on the user's profile.
 type Effect struct {
-	EndTime  int64    `json:"endTime"`
+	EndTime  int64    `json:"endTime" view:"until"`
 	Modifier Modifier `json:"modifier"`
 }
 
@@ -139,8 +145,8 @@ type Effect struct {
 type Modifier struct {
 	Type       string `json:"type"`
 	Action     string `json:"action,omitempty"`
-	Duration   int64  `json:"duration"`
-	Multiplier int64  `json:"multiplier"`
+	Duration   int64  `json:"duration" view:"duration"`
+	Multiplier int64  `json:"multiplier" key:"mult"`
 }
 
 // Upgrades represents the user's profile upgrades.
@@ -210,7 +216,7 @@ type Custom struct {
 
 // BoostStep represents a step in the faction boost system.
 type BoostStep struct {
-	LastChange int64 `json:"lastChange"`
+	LastChange int64 `json:"lastChange" view:"since"`
 	Amount     int   `json:"amount"`
 }
 
@@ -233,22 +239,22 @@ type Faction struct {
 	About                  string                `json:"about"`
 	Motd                   string                `json:"motd"`
 	UnsyncedFp             int64                 `json:"unsyncedFp"`
-	LastFpSync             string                `json:"lastFpSync"`
+	LastFpSync             string                `json:"lastFpSync" view:"since"`
 	BoostSteps             map[string]BoostStep  `json:"boostSteps"`
 	Halls                  int64                 `json:"halls"`
 	FpDepositedMonthly     int64                 `json:"fpDepositedMonthly"`
 	FpDepositedTotal       int64                 `json:"fpDepositedTotal"`
 	CustomizationSettings  CustomizationSettings `json:"customizationSettings"`
-	OwnerPremiumExpiryDate string                `json:"ownerPremiumExpiryDate"`
+	OwnerPremiumExpiryDate string                `json:"ownerPremiumExpiryDate" view:"until"`
 	MemberCount            int                   `json:"memberCount"`
 	PendingRequests        int                   `json:"pendingRequests"`
 }
 
 // LbPositions represents the leaderboard positions for a profile.
 type LbPositions struct {
-	Rank                   int `json:"rank"`
-	IncomeDaily            int `json:"incomeDaily"`
-	NetCoinflipProfitDaily int `json:"netCoinflipProfitDaily"`
+	Rank                   int `json:"rank" better:"lower"`
+	IncomeDaily            int `json:"incomeDaily" better:"lower"`
+	NetCoinflipProfitDaily int `json:"netCoinflipProfitDaily" better:"lower"`
 }
 
 var infoCmd = &cobra.Command{
@@ -273,6 +279,21 @@ var userCmd = &cobra.Command{
 func executeProfileCmd(cmd *cobra.Command, args []string, payloadType string) {
 	userID := common.ParseID(args[0])
 
+	// parse flags before fetching so typos fail fast
+	filterFlag, _ := cmd.Flags().GetString("filter")
+	sortFlag, _ := cmd.Flags().GetString("sort")
+	whereFlags, _ := cmd.Flags().GetStringArray("where")
+	filters, err := normalizeFilter(parseFilter(filterFlag))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	q, err := parseQuery(sortFlag, whereFlags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
 	// handle debug flag early so we do not unmarshal twice
 	if debug, _ := cmd.Flags().GetBool("debug"); debug {
 		payload := map[string]any{"type": payloadType, "id": userID}
@@ -281,245 +302,38 @@ func executeProfileCmd(cmd *cobra.Command, args []string, payloadType string) {
 		return
 	}
 
-	// fetch + unmarshal
-	payload := map[string]any{"type": payloadType, "id": userID}
-	raw := client.FetchDataOrExit(payload)
-	var profile ProfileInfo
-	if err := json.Unmarshal(raw, &profile); err != nil {
-		fmt.Println("error decoding profile info:", err)
-		os.Exit(1)
-	}
-
-	// parse flags
-	filterFlag, _ := cmd.Flags().GetString("filter")
-	sortFlag, _ := cmd.Flags().GetString("sort")
-	filters := parseFilter(filterFlag)
-
-	renderProfile(profile, filters, sortFlag)
-}
-
-// ===============================
-// Rendering helpers
-// ===============================
-
-type sectionWriter struct {
-	tw *tabwriter.Writer
+	renderProfile(decodeProfile(payloadType, userID), filters, q)
 }
 
-func newSectionWriter() *sectionWriter {
-	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
-	return &sectionWriter{tw: tw}
+// Fetch returns the profile of a player, exiting on error.
+func Fetch(id int) ProfileInfo {
+	return decodeProfile("profile", id)
 }
 
-func (sw *sectionWriter) flush() { _ = sw.tw.Flush() }
-
-func (sw *sectionWriter) title(t string) { fmt.Fprintf(sw.tw, "\n=== %s ===\n", strings.ToUpper(t)) }
-
-func (sw *sectionWriter) row(k, v string) { fmt.Fprintf(sw.tw, "%s:\t%s\n", k, v) }
-
-// renderProfile prints every possible field of ProfileInfo.
-// If filters is non-empty only the requested sections are rendered.
-func renderProfile(p ProfileInfo, filters map[string]bool, sortFlag string) {
-
-	itemData, err := common.LoadItemData("itemid.json", 3600)
+// Lookup returns the profile of a player, for callers that can carry on
+// without it.
+func Lookup(id int) (ProfileInfo, error) {
+	raw, err := client.FetchData(map[string]any{"type": "profile", "id": id})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "could not load item data: %v\n", err)
-		os.Exit(1)
-	}
-
-	sw := newSectionWriter()
-	defer sw.flush()
-
-	want := func(name string) bool {
-		return len(filters) == 0 || filters[strings.ToLower(name)]
+		return ProfileInfo{}, err
 	}
-
-	// Basic section
-	if want("basic") {
-		sw.title("Basic")
-		sw.row("ID", strconv.Itoa(p.ID))
-		sw.row("Name", p.Name)
-		sw.row("Registered", p.RegistrationDate)
-		sw.row("Rank", strconv.Itoa(p.Rank))
-		sw.row("Tier", strconv.Itoa(p.Tier))
-		sw.row("BC", fmt.Sprintf("%d", p.BC))
-		sw.row("SP", fmt.Sprintf("%d", p.SP))
-		sw.row("KR", fmt.Sprintf("%d", p.KR))
-		sw.row("Quest Level", strconv.Itoa(p.QuestLevel))
-		sw.row("Daily Streak", strconv.Itoa(p.DailyClaimStreak))
-	}
-
-	// Faction
-	if want("faction") && p.FactionID != 0 {
-		sw.title("Faction")
-		sw.row("Tag", p.Faction.Tag)
-		sw.row("Name", p.Faction.Name)
-		sw.row("Member Count", strconv.Itoa(p.Faction.MemberCount))
-		sw.row("Owner", strconv.FormatInt(p.Faction.OwnerBcID, 10))
-		sw.row("About", p.Faction.About)
-		sw.row("MOTD", p.Faction.Motd)
-	}
-
-	// Farm Plots
-	if want("farms") {
-		sw.title("Farm Plots")
-		plots := append([]FarmPlot(nil), p.FarmPlots...) // copy to avoid mutation
-		sortFarmPlots(plots, sortFlag)
-		for i, fp := range plots {
-			prefix := fmt.Sprintf("Plot %-3d", i+1)
-
-			rowText := fmt.Sprintf("Level: %-2d | Extra: %-5t | Planted: %-5t",
-				fp.Level,
-				fp.IsExtra,
-				fp.Status.IsPlanted)
-
-			if fp.Status.IsPlanted {
-				rowText += fmt.Sprintf("\n%-14s (%-3d) | Planted On: %s",
-					common.LookUpItemName(fp.Status.ItemID, itemData),
-					fp.Status.ItemID,
-					common.EpochToISO8601(fp.Status.PlantedTime))
-			}
-
-			if fp.Boost.Multiplier > 1 {
-				rowText += fmt.Sprintf(" | Boost x%d | Ends at %s",
-					fp.Boost.Multiplier,
-					common.EpochToISO8601(fp.Boost.EndTime))
-			}
-
-			sw.row(prefix, rowText)
-			fmt.Println()
-		}
-	}
-
-	// Generators
-	if want("generators") {
-		sw.title("Generators")
-		for i, g := range p.Generators {
-			prefix := fmt.Sprintf("Gen %d", i+1)
-			rowText := fmt.Sprintf("Level: %-2d | Extra: %-5t", g.Level, g.IsExtra)
-
-			sw.row(prefix, rowText)
-		}
-	}
-
-	// Quests
-	if want("quests") {
-		sw.title("Quests")
-		for i, q := range p.Quests {
-			prefix := fmt.Sprintf("Quest %d", i+1)
-
-			rowText := fmt.Sprintf("%-18s (%-3d)\nRequired:  %-8d | Fulfilled: %d\n",
-				common.LookUpItemName(q.ItemID, itemData),
-				q.ItemID,
-				q.AmountRequired,
-				q.AmountFulfilled)
-
-			sw.row(prefix, rowText)
-		}
-	}
-
-	// Cooldowns
-	if want("cooldowns") {
-		sw.title("Cooldowns")
-		sw.row("Fish", common.EpochToISO8601(p.Cooldowns.Fish)+" Last Used: "+common.ElapsedSinceISO8601(common.EpochToISO8601(p.Cooldowns.Fish)))
-		sw.row("Hunt", common.EpochToISO8601(p.Cooldowns.Hunt)+" Last Used: "+common.ElapsedSinceISO8601(common.EpochToISO8601(p.Cooldowns.Hunt)))
-		sw.row("Explore", common.EpochToISO8601(p.Cooldowns.Explore)+" Last Used: "+common.ElapsedSinceISO8601(common.EpochToISO8601(p.Cooldowns.Explore)))
-		sw.row("Mine", common.EpochToISO8601(p.Cooldowns.Mine)+" Last Used: "+common.ElapsedSinceISO8601(common.EpochToISO8601(p.Cooldowns.Mine)))
-		sw.row("Work", common.EpochToISO8601(p.Cooldowns.Work)+" Last Used: "+common.ElapsedSinceISO8601(common.EpochToISO8601(p.Cooldowns.Work)))
-		sw.row("Daily", common.EpochToISO8601(p.Cooldowns.Daily)+" Last Used: "+common.ElapsedSinceISO8601(common.EpochToISO8601(p.Cooldowns.Daily)))
-		sw.row("Water", common.EpochToISO8601(p.Cooldowns.Water)+" Last Used: "+common.ElapsedSinceISO8601(common.EpochToISO8601(p.Cooldowns.Water)))
-		sw.row("ClaimGenerators", common.EpochToISO8601(p.Cooldowns.ClaimGenerators)+" Last Used: "+common.ElapsedSinceISO8601(common.EpochToISO8601(p.Cooldowns.ClaimGenerators)))
-	}
-
-	// Effects / Modifiers
-	if want("effects") {
-		sw.title("Effects")
-		keys := make([]string, 0, len(p.Effects))
-		for k := range p.Effects {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-		for _, k := range keys {
-			e := p.Effects[k]
-			sw.row(k+" End", strconv.FormatInt(e.EndTime, 10))
-			sw.row(k+" Type", e.Modifier.Type)
-			if e.Modifier.Action != "" {
-				sw.row(k+" Action", e.Modifier.Action)
-			}
-			sw.row(k+" Mult x", strconv.FormatInt(e.Modifier.Multiplier, 10))
-		}
-	}
-
-	// Upgrades
-	if want("upgrades") {
-		sw.title("Upgrades")
-		sw.row("Fish", strconv.Itoa(p.Upgrades.Fish))
-		sw.row("FishExtra", strconv.Itoa(p.Upgrades.FishExtra))
-		sw.row("Hunt", strconv.Itoa(p.Upgrades.Hunt))
-		sw.row("HuntExtra", strconv.Itoa(p.Upgrades.HuntExtra))
-		sw.row("Explore", strconv.Itoa(p.Upgrades.Explore))
-		sw.row("ExploreExtra", strconv.Itoa(p.Upgrades.ExploreExtra))
-		sw.row("Mine", strconv.Itoa(p.Upgrades.Mine))
-		sw.row("MineExtra", strconv.Itoa(p.Upgrades.MineExtra))
-		sw.row("PetsStable", strconv.Itoa(p.Upgrades.PetsStable))
-		sw.row("PetsStableExtra", strconv.Itoa(p.Upgrades.PetsStableExtra))
-	}
-
-	// Perks
-	if want("perks") {
-		sw.title("Perks")
-		sw.row("LowerRankCost", strconv.Itoa(p.Perks.LowerRankCost))
-		sw.row("LowerTierCost", strconv.Itoa(p.Perks.LowerTierCost))
-		sw.row("RaisePetSpace", strconv.Itoa(p.Perks.RaisePetSpace))
-		sw.row("RaiseEquipSlots", strconv.Itoa(p.Perks.RaiseEquipSlots))
-		// (add remaining perks as needed)
-	}
-
-	// Settings
-	if want("settings") {
-		sw.title("Settings")
-		if p.Settings.ProfileShowStatID != "" {
-			sw.row("ProfileShowStatID", p.Settings.ProfileShowStatID)
-		}
-		sw.row("SyncDiscordName", fmt.Sprintf("%t", p.Settings.SyncDiscordName))
-		sw.row("PublicDiscordProfile", fmt.Sprintf("%t", p.Settings.PublicDiscordProfile))
-		sw.row("DiscordPingOnResponse", fmt.Sprintf("%t", p.Settings.DiscordPingOnResponse))
-	}
-
-	// Custom
-	if want("custom") {
-		sw.title("Custom")
-		sw.row("HideAvatar", fmt.Sprintf("%t", p.Custom.ProfileHideAvatar))
-		sw.row("HideTitleName", fmt.Sprintf("%t", p.Custom.ProfileHideTitleName))
-		sw.row("UseChatEmblemEmoji", fmt.Sprintf("%t", p.Custom.ProfileUseChatEmblemEmoji))
-		if p.Custom.ProfileBackground != nil {
-			sw.row("Background", *p.Custom.ProfileBackground)
-		}
+	var profile ProfileInfo
+	if err := json.Unmarshal(raw, &profile); err != nil {
+		return ProfileInfo{}, err
 	}
+	return profile, nil
 }
 
-// sortFarmPlots sorts plots in-place when the user provided a sort flag like "farm:plant".
-// Currently supported keys: plant, level.
-func sortFarmPlots(plots []FarmPlot, sortFlag string) {
-	if len(plots) == 0 || sortFlag == "" {
-		return
-	}
-	parts := strings.Split(sortFlag, ":")
-	if len(parts) != 2 || strings.ToLower(parts[0]) != "farm" {
-		return // not meant for farms
-	}
-
-	key := strings.ToLower(parts[1])
-	switch key {
-	case "plant", "item":
-		sort.SliceStable(plots, func(i, j int) bool {
-			return plots[i].Status.ItemID < plots[j].Status.ItemID
-		})
-	case "level":
-		sort.SliceStable(plots, func(i, j int) bool {
-			return plots[i].Level < plots[j].Level
-		})
+// decodeProfile fetches and unmarshals a profile, exiting on error.
+func decodeProfile(payloadType string, userID int) ProfileInfo {
+	payload := map[string]any{"type": payloadType, "id": userID}
+	raw := client.FetchDataOrExit(payload)
+	var profile ProfileInfo
+	if err := json.Unmarshal(raw, &profile); err != nil {
+		fmt.Println("error decoding profile info:", err)
+		os.Exit(1)
 	}
+	return profile
 }
 
 // ===============================
@@ -565,27 +379,3 @@ var flatinventoryCmd = &cobra.Command{
 		common.PrintJSON(data)
 	},
 }
-
-var statsCmd = &cobra.Command{
-	Use:   "stats [id]",
-	Short: "Fetch stats",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id := common.ParseID(args[0])
-		payload := map[string]interface{}{"type": "stats", "id": id}
-		data := client.FetchDataOrExit(payload)
-		common.PrintJSON(data)
-	},
-}
-
-var trophiesCmd = &cobra.Command{
-	Use:   "trophies [id]",
-	Short: "Fetch trophies",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id := common.ParseID(args[0])
-		payload := map[string]interface{}{"type": "trophies", "id": id}
-		data := client.FetchDataOrExit(payload)
-		common.PrintJSON(data)
-	},
-}
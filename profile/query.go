@@ -0,0 +1,287 @@
+package profile
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"bcncli/query"
+)
+
+// Sort and filter expressions work, through the shared query engine, on
+// every list section of ProfileInfo,
+// i.e. every slice or map field. Keys are the JSON names of the element's
+// fields, addressed by dotted path (status.isPlanted), by the leaf name when
+// it is unambiguous (isPlanted), or by an alias from a `key:"a,b"` tag.
+// Exported methods without arguments are computed keys (Quest.Missing is
+// "missing"). Map sections also expose "key" and scalar sections "value".
+//
+//	--sort  section:key[:asc|desc][,section:key...]
+//	--where section.key<op>value   with op one of = != > >= < <=
+
+// keyGetter extracts one named value from a section entry.
+type keyGetter struct {
+	name string
+	typ  reflect.Type
+	get  func(entry) reflect.Value
+}
+
+// value returns the key of e for comparison.
+func (k *keyGetter) value(e entry) any {
+	v := k.get(e)
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// listSection is a slice or map section with its addressable keys.
+type listSection struct {
+	name   string
+	byName map[string]*keyGetter
+}
+
+// sectionQuery holds the sort terms and conditions per canonical section
+// name. A nil sectionQuery leaves every section untouched.
+type sectionQuery struct {
+	sorts  map[string][]query.Term[entry]
+	wheres map[string][]query.Condition[entry]
+}
+
+// parseQuery parses the --sort and --where flags against ProfileInfo.
+func parseQuery(sortFlag string, wheres []string) (*sectionQuery, error) {
+	sections := listSections()
+	q := &sectionQuery{sorts: map[string][]query.Term[entry]{}, wheres: map[string][]query.Condition[entry]{}}
+
+	if sortFlag != "" {
+		for _, term := range strings.Split(sortFlag, ",") {
+			parts := strings.Split(strings.TrimSpace(term), ":")
+			if len(parts) < 2 || len(parts) > 3 {
+				return nil, fmt.Errorf("invalid sort term %q, expected section:key[:asc|desc]", term)
+			}
+			sec, key, err := lookupKey(sections, parts[0], parts[1])
+			if err != nil {
+				return nil, err
+			}
+			desc := false
+			if len(parts) == 3 {
+				if desc, err = query.Direction(parts[2]); err != nil {
+					return nil, err
+				}
+			}
+			q.sorts[sec.name] = append(q.sorts[sec.name], query.Term[entry]{Value: key.value, Desc: desc})
+		}
+	}
+
+	for _, expr := range wheres {
+		lhs, op, value, err := query.Split(expr)
+		if err != nil {
+			return nil, err
+		}
+		secName, keyName, ok := strings.Cut(lhs, ".")
+		if !ok {
+			return nil, fmt.Errorf("invalid condition %q, expected section.key<op>value", expr)
+		}
+		sec, key, err := lookupKey(sections, secName, keyName)
+		if err != nil {
+			return nil, err
+		}
+		lit, err := query.Literal(key.name, query.KindOf(key.typ), value)
+		if err != nil {
+			return nil, err
+		}
+		q.wheres[sec.name] = append(q.wheres[sec.name], query.Condition[entry]{Value: key.value, Op: op, Literal: lit})
+	}
+
+	return q, nil
+}
+
+// apply filters and sorts the entries of the named section.
+func (q *sectionQuery) apply(section string, entries []entry) []entry {
+	if q == nil {
+		return entries
+	}
+	entries = query.Filter(entries, q.wheres[section])
+	query.Sort(entries, q.sorts[section])
+	return entries
+}
+
+// normalizeFilter maps --filter names and aliases to canonical section names.
+func normalizeFilter(filters map[string]bool) (map[string]bool, error) {
+	if len(filters) == 0 {
+		return filters, nil
+	}
+	canonical := map[string]string{basicSection: basicSection}
+	valid := []string{basicSection}
+	t := reflect.TypeOf(ProfileInfo{})
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() || isScalar(sf.Type) {
+			continue
+		}
+		names := sectionNames(sf)
+		valid = append(valid, names[0])
+		for _, n := range names {
+			canonical[n] = names[0]
+		}
+	}
+
+	out := make(map[string]bool, len(filters))
+	for f := range filters {
+		name, ok := canonical[f]
+		if !ok {
+			return nil, fmt.Errorf("unknown section %q (valid: %s)", f, strings.Join(valid, ", "))
+		}
+		out[name] = true
+	}
+	return out, nil
+}
+
+// listSections indexes every slice and map section of ProfileInfo by all of
+// its names.
+func listSections() map[string]*listSection {
+	out := make(map[string]*listSection)
+	t := reflect.TypeOf(ProfileInfo{})
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() || (sf.Type.Kind() != reflect.Slice && sf.Type.Kind() != reflect.Map) {
+			continue
+		}
+		names := sectionNames(sf)
+		sec := &listSection{name: names[0], byName: sectionKeys(sf.Type)}
+		for _, n := range names {
+			out[n] = sec
+		}
+	}
+	return out
+}
+
+// lookupKey resolves a section and key name, reporting the valid choices
+// when either is unknown.
+func lookupKey(sections map[string]*listSection, secName, keyName string) (*listSection, *keyGetter, error) {
+	sec, ok := sections[strings.ToLower(strings.TrimSpace(secName))]
+	if !ok {
+		var valid []string
+		for n, s := range sections {
+			if n == s.name {
+				valid = append(valid, n)
+			}
+		}
+		sort.Strings(valid)
+		return nil, nil, fmt.Errorf("unknown list section %q (valid: %s)", secName, strings.Join(valid, ", "))
+	}
+	key, ok := sec.byName[strings.ToLower(strings.TrimSpace(keyName))]
+	if !ok {
+		valid := make([]string, 0, len(sec.byName))
+		for n := range sec.byName {
+			valid = append(valid, n)
+		}
+		sort.Strings(valid)
+		return nil, nil, fmt.Errorf("unknown key %q for section %s (valid: %s)", keyName, sec.name, strings.Join(valid, ", "))
+	}
+	return sec, key, nil
+}
+
+// sectionKeys lists the keys of a slice or map type, indexed by lower-cased
+// name, path and alias.
+func sectionKeys(t reflect.Type) map[string]*keyGetter {
+	byName := make(map[string]*keyGetter)
+	add := func(name string, k *keyGetter) {
+		name = strings.ToLower(name)
+		if _, exists := byName[name]; !exists {
+			byName[name] = k
+		}
+	}
+
+	if t.Kind() == reflect.Map {
+		add("key", &keyGetter{name: "key", typ: t.Key(), get: func(e entry) reflect.Value { return e.key }})
+	}
+
+	elem := t.Elem()
+	if isScalar(elem) {
+		add("value", &keyGetter{name: "value", typ: elem, get: func(e entry) reflect.Value { return e.value }})
+		return byName
+	}
+	if elem.Kind() != reflect.Struct {
+		return byName
+	}
+
+	// struct fields, by path, leaf name (when unique) and aliases
+	type leaf struct {
+		path    string
+		name    string
+		aliases []string
+		key     *keyGetter
+	}
+	var leaves []leaf
+	var walk func(t reflect.Type, index []int, path string)
+	walk = func(t reflect.Type, index []int, path string) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			if name == "" {
+				name = sf.Name
+			}
+			idx := append(append([]int(nil), index...), i)
+			full := joinLabel(path, name)
+			if sf.Type.Kind() == reflect.Struct {
+				walk(sf.Type, idx, full)
+				continue
+			}
+			if !isScalar(sf.Type) {
+				continue
+			}
+			var aliases []string
+			if tag := sf.Tag.Get("key"); tag != "" {
+				aliases = strings.Split(tag, ",")
+			}
+			leaves = append(leaves, leaf{
+				path:    full,
+				name:    name,
+				aliases: aliases,
+				key: &keyGetter{name: full, typ: sf.Type, get: func(e entry) reflect.Value {
+					return e.value.FieldByIndex(idx)
+				}},
+			})
+		}
+	}
+	walk(elem, nil, "")
+
+	counts := make(map[string]int)
+	for _, l := range leaves {
+		counts[strings.ToLower(l.name)]++
+	}
+	for _, l := range leaves {
+		add(l.path, l.key)
+		if counts[strings.ToLower(l.name)] == 1 {
+			add(l.name, l.key)
+		}
+		for _, a := range l.aliases {
+			add(a, l.key)
+		}
+	}
+
+	// computed keys from argument-less methods
+	for i := 0; i < elem.NumMethod(); i++ {
+		m := elem.Method(i)
+		if m.Type.NumIn() != 1 || m.Type.NumOut() != 1 || !isScalar(m.Type.Out(0)) {
+			continue
+		}
+		name := lowerFirst(m.Name)
+		add(name, &keyGetter{name: name, typ: m.Type.Out(0), get: func(e entry) reflect.Value {
+			return e.value.MethodByName(m.Name).Call(nil)[0]
+		}})
+	}
+	return byName
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
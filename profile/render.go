@@ -0,0 +1,464 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"bcncli/client"
+	"bcncli/common"
+)
+
+// Profile rendering is driven by the ProfileInfo type itself: every exported
+// field is printed, so new fields show up without new code. Top-level scalar
+// fields make up the "basic" section; every other top-level field is its own
+// section. Two struct tags control presentation:
+//
+//	section:"a,b"   overrides the --filter name of a top-level field
+//	                (defaults to the lower-cased JSON name); extra
+//	                names are accepted as aliases
+//	view:"kind"     formats the value; on maps it applies to the keys and
+//	                on slices to the elements:
+//	                  item      item ID resolved to the item name
+//	                  pet       pet ID resolved to the pet name
+//	                  since     timestamp with the time elapsed since then
+//	                  until     timestamp with the time remaining until then
+//	                  duration  milliseconds shown as a duration
+//	                  stock     amounts indexed by item ID; the item names
+//	                            label the rows and empty ones are left out
+const (
+	viewItem     = "item"
+	viewPet      = "pet"
+	viewSince    = "since"
+	viewUntil    = "until"
+	viewDuration = "duration"
+	viewStock    = "stock"
+)
+
+// basicSection is the section holding all top-level scalar fields.
+const basicSection = "basic"
+
+type sectionWriter struct {
+	tw *tabwriter.Writer
+}
+
+func newSectionWriter() *sectionWriter {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	return &sectionWriter{tw: tw}
+}
+
+func (sw *sectionWriter) flush() { _ = sw.tw.Flush() }
+
+func (sw *sectionWriter) title(t string) { fmt.Fprintf(sw.tw, "\n=== %s ===\n", strings.ToUpper(t)) }
+
+func (sw *sectionWriter) row(k, v string) { fmt.Fprintf(sw.tw, "%s:\t%s\n", k, v) }
+
+func (sw *sectionWriter) note(s string) { fmt.Fprintln(sw.tw, s) }
+
+func (sw *sectionWriter) cells(c []string) { fmt.Fprintln(sw.tw, strings.Join(c, "\t")) }
+
+// field is a single flattened label/value pair.
+type field struct {
+	label string
+	value string
+}
+
+// renderer formats reflected profile values, resolving item and pet IDs.
+type renderer struct {
+	items    []common.Item
+	petNames map[int64]string
+}
+
+func newRenderer(items []common.Item) *renderer {
+	return &renderer{items: items, petNames: make(map[int64]string)}
+}
+
+// petName fetches the name of a pet, memoizing the result. A failed lookup
+// is reported on stderr and yields "Unknown Pet ID", so one missing pet does
+// not abort the profile.
+func (r *renderer) petName(id int64) string {
+	if name, ok := r.petNames[id]; ok {
+		return name
+	}
+	name := fmt.Sprintf("Unknown Pet ID %d", id)
+	raw, err := client.FetchData(map[string]any{"type": "pet", "id": id})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: pet %d: %v\n", id, err)
+		r.petNames[id] = name
+		return name
+	}
+	var p struct {
+		Name    string `json:"name"`
+		Species string `json:"species"`
+	}
+	if err := json.Unmarshal(raw, &p); err == nil && p.Name != "" {
+		name = p.Name
+		if p.Species != "" {
+			name += " the " + p.Species
+		}
+	}
+	r.petNames[id] = name
+	return name
+}
+
+// renderProfile prints every field of ProfileInfo.
+// If filters is non-empty only the requested sections are rendered;
+// list sections are sorted and filtered according to q.
+func renderProfile(p ProfileInfo, filters map[string]bool, q *sectionQuery) {
+	itemData, err := common.LoadItemData("itemid.json", 3600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load item data: %v\n", err)
+		os.Exit(1)
+	}
+
+	r := newRenderer(itemData)
+	sw := newSectionWriter()
+	defer sw.flush()
+
+	want := func(name string) bool {
+		return len(filters) == 0 || filters[name]
+	}
+
+	v := reflect.ValueOf(p)
+	t := v.Type()
+
+	if want(basicSection) {
+		sw.title("Basic")
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.IsExported() && isScalar(sf.Type) {
+				sw.row(sf.Name, r.format(v.Field(i), sf.Tag.Get("view")))
+			}
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() || isScalar(sf.Type) {
+			continue
+		}
+		name := sectionNames(sf)[0]
+		if !want(name) {
+			continue
+		}
+		sw.title(humanize(sf.Name))
+		fv := v.Field(i)
+		view := sf.Tag.Get("view")
+		if fv.Kind() == reflect.Slice || fv.Kind() == reflect.Map {
+			r.renderEntries(sw, fv.Type(), q.apply(name, entriesOf(fv)), view)
+			continue
+		}
+		r.renderSection(sw, fv, view)
+	}
+}
+
+// renderSection prints a single struct or scalar top-level field.
+func (r *renderer) renderSection(sw *sectionWriter, v reflect.Value, view string) {
+	if v.Kind() != reflect.Struct {
+		sw.row("Value", r.format(v, view))
+		return
+	}
+	if v.IsZero() {
+		sw.note("(none)")
+		return
+	}
+	for _, f := range r.flatten("", v, "") {
+		sw.row(f.label, f.value)
+	}
+}
+
+// renderEntries prints the entries of a slice or map section of type t.
+// Entries of structs become a table, scalar entries one row each.
+func (r *renderer) renderEntries(sw *sectionWriter, t reflect.Type, entries []entry, view string) {
+	if len(entries) == 0 {
+		sw.note("(none)")
+		return
+	}
+	isMap := t.Kind() == reflect.Map
+
+	// label is the first column: the map key or the 1-based slice position.
+	label := func(e entry) string {
+		if isMap {
+			return r.format(e.key, view)
+		}
+		if view == viewStock {
+			return r.format(reflect.ValueOf(e.index), viewItem)
+		}
+		if isScalar(t.Elem()) {
+			return fmt.Sprintf("#%d", e.index+1)
+		}
+		return strconv.Itoa(e.index + 1)
+	}
+
+	if isScalar(t.Elem()) {
+		elemView := view
+		if isMap || view == viewStock {
+			elemView = ""
+		}
+		shown := 0
+		for _, e := range entries {
+			if view == viewStock && e.value.IsZero() {
+				continue
+			}
+			sw.row(label(e), r.format(e.value, elemView))
+			shown++
+		}
+		if shown == 0 {
+			sw.note("(none)")
+		}
+		return
+	}
+
+	for i, e := range entries {
+		fields := r.flatten("", e.value, "")
+		if i == 0 {
+			head := "#"
+			if isMap {
+				head = "Key"
+			}
+			sw.cells(append([]string{head}, labels(fields)...))
+		}
+		sw.cells(append([]string{label(e)}, values(fields)...))
+	}
+}
+
+// entry is a single element of a slice or map section.
+type entry struct {
+	index int           // position in the slice, or in key order for maps
+	key   reflect.Value // map key; invalid for slices
+	value reflect.Value
+}
+
+// entriesOf lists the elements of a slice, or of a map in key order.
+func entriesOf(v reflect.Value) []entry {
+	var out []entry
+	if v.Kind() == reflect.Map {
+		for i, k := range sortedKeys(v) {
+			out = append(out, entry{index: i, key: k, value: v.MapIndex(k)})
+		}
+		return out
+	}
+	for i := 0; i < v.Len(); i++ {
+		out = append(out, entry{index: i, value: v.Index(i)})
+	}
+	return out
+}
+
+// flatten turns v into label/value pairs, joining nested struct field names
+// with dots. Maps of structs are expanded per key; other maps and slices are
+// collapsed into a single comma-separated value.
+func (r *renderer) flatten(prefix string, v reflect.Value, view string) []field {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return []field{{prefix, "-"}}
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		var out []field
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			out = append(out, r.flatten(joinLabel(prefix, sf.Name), v.Field(i), sf.Tag.Get("view"))...)
+		}
+		return out
+
+	case reflect.Map:
+		if v.Len() > 0 && !isScalar(v.Type().Elem()) {
+			var out []field
+			for _, k := range sortedKeys(v) {
+				label := fmt.Sprintf("%s[%s]", prefix, r.format(k, view))
+				out = append(out, r.flatten(label, v.MapIndex(k), "")...)
+			}
+			return out
+		}
+		var parts []string
+		for _, k := range sortedKeys(v) {
+			parts = append(parts, r.format(k, view)+"="+r.format(v.MapIndex(k), ""))
+		}
+		return []field{{prefix, joinOrDash(parts)}}
+
+	case reflect.Slice, reflect.Array:
+		var parts []string
+		for i := 0; i < v.Len(); i++ {
+			parts = append(parts, r.format(v.Index(i), view))
+		}
+		return []field{{prefix, joinOrDash(parts)}}
+	}
+
+	return []field{{prefix, r.format(v, view)}}
+}
+
+// format renders a single scalar value according to its view tag.
+func (r *renderer) format(v reflect.Value, view string) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "-"
+		}
+		v = v.Elem()
+	}
+
+	switch view {
+	case viewItem:
+		if id, ok := intValue(v); ok && id > 0 {
+			return fmt.Sprintf("%s (%d)", common.LookUpItemName(int(id), r.items), id)
+		}
+	case viewPet:
+		if id, ok := intValue(v); ok && id > 0 {
+			return fmt.Sprintf("%s (%d)", r.petName(id), id)
+		}
+	case viewSince, viewUntil:
+		if t, ok := timeValue(v); ok {
+			return formatTime(t)
+		}
+		if _, isNum := intValue(v); isNum {
+			return "-"
+		}
+	case viewDuration:
+		if ms, ok := intValue(v); ok {
+			return common.FormatDuration(time.Duration(ms) * time.Millisecond)
+		}
+	}
+
+	if v.Kind() == reflect.String && v.String() == "" {
+		return "-"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// formatTime prints t together with how far it is from now.
+func formatTime(t time.Time) string {
+	iso := t.UTC().Format(time.RFC3339)
+	diff := time.Until(t)
+	if diff > 0 {
+		return fmt.Sprintf("%s (in %s)", iso, common.FormatDuration(diff))
+	}
+	return fmt.Sprintf("%s (%s ago)", iso, common.FormatDuration(diff))
+}
+
+// intValue extracts an integer from numeric kinds and numeric strings
+// (map keys are strings in JSON).
+func intValue(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return int64(v.Float()), true
+	case reflect.String:
+		n, err := strconv.ParseInt(v.String(), 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// timeValue extracts a timestamp from epoch milliseconds or an RFC3339 string.
+// Zero and empty values report false.
+func timeValue(v reflect.Value) (time.Time, bool) {
+	if v.Kind() == reflect.String {
+		t, err := time.Parse(time.RFC3339, v.String())
+		return t, err == nil
+	}
+	if ms, ok := intValue(v); ok && ms > 0 {
+		return common.EpochToTime(ms), true
+	}
+	return time.Time{}, false
+}
+
+// isScalar reports whether t (or what it points to) is a single value
+// rather than a struct, slice or map.
+func isScalar(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return false
+	}
+	return true
+}
+
+// sectionNames returns the --filter names of a top-level field,
+// canonical name first.
+func sectionNames(sf reflect.StructField) []string {
+	if tag := sf.Tag.Get("section"); tag != "" {
+		return strings.Split(tag, ",")
+	}
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		name = sf.Name
+	}
+	return []string{strings.ToLower(name)}
+}
+
+// sortedKeys returns the keys of a map in a stable order,
+// numerically when the keys are numbers.
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		a, aok := intValue(keys[i])
+		b, bok := intValue(keys[j])
+		if aok && bok {
+			return a < b
+		}
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+// humanize splits a Go field name into words, e.g. FarmPlots -> Farm Plots.
+func humanize(name string) string {
+	var b strings.Builder
+	prev := rune(0)
+	for _, c := range name {
+		if unicode.IsUpper(c) && unicode.IsLower(prev) {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(c)
+		prev = c
+	}
+	return b.String()
+}
+
+func joinLabel(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func joinOrDash(parts []string) string {
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func labels(fields []field) []string {
+	out := make([]string, len(fields))
+	for i, f := range fields {
+		out[i] = f.label
+	}
+	return out
+}
+
+func values(fields []field) []string {
+	out := make([]string, len(fields))
+	for i, f := range fields {
+		out[i] = f.value
+	}
+	return out
+}
@@ -0,0 +1,467 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"bcncli/client"
+	"bcncli/common"
+	"bcncli/query"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(compareCmd)
+
+	for _, c := range []*cobra.Command{statsCmd, trophiesCmd} {
+		c.Flags().BoolP("debug", "d", false, "print raw JSON response")
+	}
+	compareCmd.Flags().StringP("filter", "f", "", "comma-separated list of sections to compare (stats,trophies,upgrades,perks,leaderboard)")
+}
+
+// Stats holds a player's lifetime statistics. The stats response is not
+// documented, so values are grouped the way the response nests them rather
+// than by a fixed list of keys: every top-level object or list is a group,
+// and the top-level values form the General group.
+type Stats struct {
+	Groups []StatGroup
+}
+
+// Trophies holds a player's trophies, grouped like Stats. When the response
+// is a list, every trophy is a group named by its name or id.
+type Trophies struct {
+	Groups []StatGroup
+}
+
+// UnmarshalJSON groups the values of a stats response.
+func (s *Stats) UnmarshalJSON(data []byte) error {
+	groups, err := decodeGroups(data)
+	s.Groups = groups
+	return err
+}
+
+// UnmarshalJSON groups the values of a trophies response.
+func (t *Trophies) UnmarshalJSON(data []byte) error {
+	groups, err := decodeGroups(data)
+	t.Groups = groups
+	return err
+}
+
+func (s Stats) groupList() []StatGroup    { return s.Groups }
+func (t Trophies) groupList() []StatGroup { return t.Groups }
+
+// grouped is implemented by Stats and Trophies.
+type grouped interface {
+	groupList() []StatGroup
+}
+
+// StatGroup is a named group of values. Values nested deeper than the group
+// are flattened into dotted keys.
+type StatGroup struct {
+	Name   string
+	Values []StatValue
+}
+
+// StatValue is one value of a group. Whole numbers and booleans (as 0 or 1)
+// are Numeric and compared between players; booleans, fractions, strings
+// such as names or dates, and nulls keep their text in Text.
+type StatValue struct {
+	Key     string
+	Numeric bool
+	Number  int64
+	Text    string
+}
+
+// String formats the value for display.
+func (v StatValue) String() string {
+	if v.Text != "" {
+		return v.Text
+	}
+	return common.FormatPrice(v.Number, true)
+}
+
+// generalGroup holds the top-level values of a response.
+const generalGroup = "General"
+
+// decodeGroups groups a stats or trophies response: objects and lists at
+// the top level become groups, everything else goes to General. Keys are
+// sorted by name.
+func decodeGroups(data []byte) ([]StatGroup, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	general := StatGroup{Name: generalGroup}
+	var groups []StatGroup
+	add := func(name string, v any) {
+		g := StatGroup{Name: name}
+		flattenValues("", v, &g.Values)
+		if len(g.Values) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	switch t := raw.(type) {
+	case map[string]any:
+		for _, k := range sortedNames(t) {
+			switch child := t[k].(type) {
+			case map[string]any, []any:
+				add(k, child)
+			default:
+				flattenValues(k, child, &general.Values)
+			}
+		}
+	case []any:
+		for i, child := range t {
+			add(elementName(child, i), child)
+		}
+	default:
+		flattenValues("value", raw, &general.Values)
+	}
+	if len(general.Values) > 0 {
+		groups = append([]StatGroup{general}, groups...)
+	}
+	return groups, nil
+}
+
+// elementName names the i-th element of a list by its "name" or "id", or
+// by its 1-based position.
+func elementName(v any, i int) string {
+	if m, ok := v.(map[string]any); ok {
+		for _, k := range []string{"name", "id"} {
+			switch id := m[k].(type) {
+			case string:
+				if id != "" {
+					return id
+				}
+			case float64:
+				return strconv.FormatFloat(id, 'f', -1, 64)
+			}
+		}
+	}
+	return "#" + strconv.Itoa(i+1)
+}
+
+// flattenValues appends every leaf of v to out, keyed by its dotted path.
+func flattenValues(prefix string, v any, out *[]StatValue) {
+	switch t := v.(type) {
+	case map[string]any:
+		for _, k := range sortedNames(t) {
+			flattenValues(joinLabel(prefix, k), t[k], out)
+		}
+	case []any:
+		for i, child := range t {
+			flattenValues(joinLabel(prefix, strconv.Itoa(i)), child, out)
+		}
+	case float64:
+		if t == float64(int64(t)) {
+			*out = append(*out, StatValue{Key: prefix, Numeric: true, Number: int64(t)})
+		} else {
+			*out = append(*out, StatValue{Key: prefix, Text: strconv.FormatFloat(t, 'f', -1, 64)})
+		}
+	case bool:
+		sv := StatValue{Key: prefix, Numeric: true, Text: strconv.FormatBool(t)}
+		if t {
+			sv.Number = 1
+		}
+		*out = append(*out, sv)
+	case string:
+		if t == "" {
+			t = "-"
+		}
+		*out = append(*out, StatValue{Key: prefix, Text: t})
+	case nil:
+		*out = append(*out, StatValue{Key: prefix, Text: "-"})
+	}
+}
+
+// sortedNames returns the keys of m in order.
+func sortedNames(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renderGroups prints a Stats or Trophies as one table per group.
+func renderGroups(v grouped) {
+	groups := v.groupList()
+	if len(groups) == 0 {
+		fmt.Println("(none)")
+		return
+	}
+	sw := newSectionWriter()
+	defer sw.flush()
+	for _, g := range groups {
+		sw.title(g.Name)
+		for _, sv := range g.Values {
+			sw.row(sv.Key, sv.String())
+		}
+	}
+}
+
+// fetchGroups fetches the stats or trophies of a player into v.
+func fetchGroups(payloadType string, id int, v grouped) {
+	raw := client.FetchDataOrExit(map[string]any{"type": payloadType, "id": id})
+	if err := json.Unmarshal(raw, v); err != nil {
+		fmt.Fprintf(os.Stderr, "error decoding %s: %v\n", payloadType, err)
+		os.Exit(1)
+	}
+}
+
+// runGroupsCmd is shared by statsCmd and trophiesCmd; v is a *Stats or
+// *Trophies.
+func runGroupsCmd(cmd *cobra.Command, args []string, payloadType string, v grouped) {
+	id := common.ParseID(args[0])
+	if debug, _ := cmd.Flags().GetBool("debug"); debug {
+		common.PrintJSON(client.FetchDataOrExit(map[string]any{"type": payloadType, "id": id}))
+		return
+	}
+	fetchGroups(payloadType, id, v)
+	renderGroups(v)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [id]",
+	Short: "Show stats grouped by category",
+	Long: `Show a player's stats, one table per group of the response: every
+top-level object is a group and the top-level values form General. Nested
+values are shown under dotted keys; text values such as names and dates are
+kept as they are.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runGroupsCmd(cmd, args, "stats", &Stats{})
+	},
+}
+
+var trophiesCmd = &cobra.Command{
+	Use:   "trophies [id]",
+	Short: "Show trophies grouped by category",
+	Long: `Show a player's trophies, grouped like stats. When the response is a
+list, every trophy is a group named by its name or id.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runGroupsCmd(cmd, args, "trophies", &Trophies{})
+	},
+}
+
+// comparedPlayer bundles everything compareCmd shows for one player.
+type comparedPlayer struct {
+	profile  ProfileInfo
+	stats    Stats
+	trophies Trophies
+}
+
+// compareRow is one compared value per player. lowerWins marks rows where
+// the smallest non-zero value leads, such as leaderboard positions. Rows of
+// non-numeric values have texts instead of values and no leader.
+type compareRow struct {
+	category  string
+	key       string
+	values    []int64
+	texts     []string
+	lowerWins bool
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare [id1] [id2] [...]",
+	Short: "Compare players side by side",
+	Long: `Compare two or more players on stats, trophies, upgrades, perks and
+leaderboard positions. The leading value of every row is marked with "*",
+and the number of rows each player leads is summarized at the end.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		filterFlag, _ := cmd.Flags().GetString("filter")
+		filters := parseFilter(filterFlag)
+		for f := range filters {
+			if !slices.Contains(compareSections, f) {
+				fmt.Fprintf(os.Stderr, "Error: unknown section %q (valid: %s)\n", f, strings.Join(compareSections, ", "))
+				os.Exit(1)
+			}
+		}
+		want := func(name string) bool {
+			return len(filters) == 0 || filters[name]
+		}
+
+		players := make([]comparedPlayer, 0, len(args))
+		for _, arg := range args {
+			id := common.ParseID(arg)
+			p := comparedPlayer{profile: decodeProfile("profile", id)}
+			fetchGroups("stats", id, &p.stats)
+			fetchGroups("trophies", id, &p.trophies)
+			players = append(players, p)
+		}
+
+		leads := make([]int, len(players))
+		for _, section := range compareSections {
+			if !want(section) {
+				continue
+			}
+			rows := compareRows(section, players)
+			if len(rows) == 0 {
+				continue
+			}
+			fmt.Printf("\n=== %s ===\n", strings.ToUpper(section))
+			printCompareTable(players, rows, leads)
+		}
+
+		fmt.Println("\n=== LEADS ===")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for i, p := range players {
+			fmt.Fprintf(w, "%s (%d)\t%d\n", p.profile.Name, p.profile.ID, leads[i])
+		}
+		w.Flush()
+	},
+}
+
+// compareSections lists the sections of compareCmd in display order.
+var compareSections = []string{"stats", "trophies", "upgrades", "perks", "leaderboard"}
+
+// compareRows builds the rows of one compare section.
+func compareRows(section string, players []comparedPlayer) []compareRow {
+	switch section {
+	case "stats":
+		return groupRows(players, func(p comparedPlayer) grouped { return p.stats })
+	case "trophies":
+		return groupRows(players, func(p comparedPlayer) grouped { return p.trophies })
+	case "upgrades":
+		return structRows(players, func(p comparedPlayer) any { return p.profile.Upgrades })
+	case "perks":
+		return structRows(players, func(p comparedPlayer) any { return p.profile.Perks })
+	case "leaderboard":
+		return structRows(players, func(p comparedPlayer) any { return p.profile.LbPositions })
+	}
+	return nil
+}
+
+// groupRows lines up the union of all grouped values, in group order and
+// then by key. A player without a numeric value counts as 0; a row where
+// any player has a non-numeric value is shown as text.
+func groupRows(players []comparedPlayer, get func(comparedPlayer) grouped) []compareRow {
+	type rowKey struct{ group, key string }
+	var order []rowKey
+	seen := make(map[rowKey]bool)
+	values := make([]map[rowKey]StatValue, len(players))
+	for i, p := range players {
+		values[i] = make(map[rowKey]StatValue)
+		for _, g := range get(p).groupList() {
+			for _, sv := range g.Values {
+				k := rowKey{g.Name, sv.Key}
+				values[i][k] = sv
+				if !seen[k] {
+					seen[k] = true
+					order = append(order, k)
+				}
+			}
+		}
+	}
+
+	rows := make([]compareRow, 0, len(order))
+	for _, k := range order {
+		row := compareRow{category: k.group, key: k.key}
+		numeric := true
+		for i := range players {
+			if sv, ok := values[i][k]; ok && !sv.Numeric {
+				numeric = false
+			}
+		}
+		for i := range players {
+			sv, ok := values[i][k]
+			switch {
+			case numeric:
+				row.values = append(row.values, sv.Number)
+			case ok:
+				row.texts = append(row.texts, sv.String())
+			default:
+				row.texts = append(row.texts, "-")
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// structRows lines up the integer fields of a struct, honoring a
+// `better:"lower"` tag for fields where smaller values lead.
+func structRows(players []comparedPlayer, get func(comparedPlayer) any) []compareRow {
+	t := reflect.TypeOf(get(players[0]))
+	var rows []compareRow
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if query.KindOf(sf.Type) != query.Number {
+			continue
+		}
+		row := compareRow{key: sf.Name, lowerWins: sf.Tag.Get("better") == "lower"}
+		for _, p := range players {
+			v, _ := intValue(reflect.ValueOf(get(p)).Field(i))
+			row.values = append(row.values, v)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// leader returns the index of the leading value, or -1 on a tie or when
+// nobody has a value.
+func (r compareRow) leader() int {
+	best := -1
+	tie := false
+	for i, v := range r.values {
+		if r.lowerWins && v <= 0 {
+			continue
+		}
+		switch {
+		case best < 0:
+			best = i
+		case v == r.values[best]:
+			tie = true
+		case (v < r.values[best]) == r.lowerWins:
+			best, tie = i, false
+		}
+	}
+	if tie || (best >= 0 && !r.lowerWins && r.values[best] == 0) {
+		return -1
+	}
+	return best
+}
+
+// printCompareTable prints rows side by side and adds each row's leader to leads.
+func printCompareTable(players []comparedPlayer, rows []compareRow, leads []int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	head := []string{"CATEGORY", "KEY"}
+	for _, p := range players {
+		head = append(head, fmt.Sprintf("%s (%d)", p.profile.Name, p.profile.ID))
+	}
+	fmt.Fprintln(w, strings.Join(head, "\t"))
+
+	for _, r := range rows {
+		lead := r.leader()
+		if lead >= 0 {
+			leads[lead]++
+		}
+		cells := []string{r.category, r.key}
+		if r.category == "" {
+			cells[0] = "-"
+		}
+		cells = append(cells, r.texts...)
+		for i, v := range r.values {
+			cell := common.FormatPrice(v, true)
+			if i == lead {
+				cell += " *"
+			}
+			cells = append(cells, cell)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
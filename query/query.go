@@ -0,0 +1,231 @@
+// Package query holds the --sort and --where engine shared by the profile
+// sections and the pet and egg tables. Callers resolve key names to getters;
+// this package parses operators, directions and literals, and compares
+// values the same way everywhere:
+//
+//	--sort  key[:asc|desc][,key...]
+//	--where key<op>value   with op one of = != > >= < <=
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Ops lists the supported operators, two-character ones first.
+var Ops = []string{"!=", ">=", "<=", "=", ">", "<"}
+
+// Kind classifies values for comparison.
+type Kind int
+
+const (
+	String Kind = iota
+	Number
+	Bool
+)
+
+// KindOf returns the kind of values of type t (or what it points to).
+func KindOf(t reflect.Type) Kind {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return String
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return Number
+	case reflect.Bool:
+		return Bool
+	}
+	return String
+}
+
+// Split splits "key<op>value" at the first operator. Quotes around value
+// are removed.
+func Split(expr string) (key, op, value string, err error) {
+	i := strings.IndexAny(expr, "!<>=")
+	if i <= 0 {
+		return "", "", "", fmt.Errorf("invalid condition %q, expected key<op>value (op: %s)", expr, strings.Join(Ops, " "))
+	}
+	for _, o := range Ops {
+		if strings.HasPrefix(expr[i:], o) {
+			value = strings.TrimSpace(expr[i+len(o):])
+			value = strings.Trim(value, `"'`)
+			return strings.TrimSpace(expr[:i]), o, value, nil
+		}
+	}
+	return "", "", "", fmt.Errorf("invalid operator in %q (valid: %s)", expr, strings.Join(Ops, " "))
+}
+
+// Literal parses a --where value for comparison with key, which holds
+// values of kind k. A value that cannot be compared is an error.
+func Literal(key string, k Kind, value string) (any, error) {
+	switch k {
+	case Number:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("key %s is numeric, got %q", key, value)
+		}
+		return f, nil
+	case Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s is true/false, got %q", key, value)
+		}
+		return b, nil
+	}
+	return value, nil
+}
+
+// Direction parses a sort direction; empty means ascending.
+func Direction(dir string) (desc bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(dir)) {
+	case "", "asc":
+		return false, nil
+	case "desc":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid sort direction %q, must be asc or desc", dir)
+}
+
+// Condition is a parsed comparison of a key of T against a literal.
+type Condition[T any] struct {
+	Value   func(T) any
+	Op      string
+	Literal any
+}
+
+// Match reports whether row satisfies the condition.
+func (c Condition[T]) Match(row T) bool {
+	cmp := Compare(c.Value(row), c.Literal)
+	switch c.Op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// Filter keeps the rows matching every condition.
+func Filter[T any](rows []T, conds []Condition[T]) []T {
+	if len(conds) == 0 {
+		return rows
+	}
+	kept := rows[:0:0]
+	for _, r := range rows {
+		ok := true
+		for _, c := range conds {
+			if !c.Match(r) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// Term is a parsed sort key of T.
+type Term[T any] struct {
+	Value func(T) any
+	Desc  bool
+}
+
+// Sort orders rows by terms, earlier terms first. Equal rows keep their
+// order.
+func Sort[T any](rows []T, terms []Term[T]) {
+	if len(terms) == 0 {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, t := range terms {
+			c := Compare(t.Value(rows[i]), t.Value(rows[j]))
+			if c == 0 {
+				continue
+			}
+			if t.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// Compare orders two scalars: numbers numerically, false before true, and
+// everything else as case-insensitive strings. Numeric strings, such as
+// item ID map keys, still compare as numbers.
+func Compare(a, b any) int {
+	x, y := deref(reflect.ValueOf(a)), deref(reflect.ValueOf(b))
+	if KindOf(x.Type()) == Number && KindOf(y.Type()) == Number {
+		return compareFloats(toFloat(x), toFloat(y))
+	}
+	if x.Kind() == reflect.Bool && y.Kind() == reflect.Bool {
+		switch {
+		case x.Bool() == y.Bool():
+			return 0
+		case y.Bool():
+			return -1
+		}
+		return 1
+	}
+	xs, ys := fmt.Sprint(x.Interface()), fmt.Sprint(y.Interface())
+	if fx, err := strconv.ParseFloat(xs, 64); err == nil {
+		if fy, err := strconv.ParseFloat(ys, 64); err == nil {
+			return compareFloats(fx, fy)
+		}
+	}
+	return strings.Compare(strings.ToLower(xs), strings.ToLower(ys))
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+// deref follows pointers and interfaces; nil becomes an empty string so it
+// sorts first.
+func deref(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return reflect.ValueOf("")
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.ValueOf("")
+		}
+		v = v.Elem()
+	}
+	return v
+}
@@ -2,6 +2,9 @@
 package search
 
 import (
+	"fmt"
+	"os"
+
 	"bcncli/client"
 	"bcncli/common"
 
@@ -66,14 +69,29 @@ var petCmd = &cobra.Command{
 		species, _ := cmd.Flags().GetString("species")
 		name, _ := cmd.Flags().GetString("name")
 
-		payload := map[string]interface{}{
-			"type":         "searchPets",
-			"skin":         skin,
-			"aura":         aura,
-			"species":      species,
-			"rawNameQuery": name,
-		}
-		data := client.FetchDataOrExit(payload)
-		common.PrintJSON(data)
+		common.PrintJSON(FetchPets(skin, aura, species, name))
 	},
 }
+
+// SearchPets runs a searchPets query and returns the raw response.
+// Pass "any skin", "any aura" and "any species" to leave a filter open.
+func SearchPets(skin, aura, species, name string) ([]byte, error) {
+	payload := map[string]interface{}{
+		"type":         "searchPets",
+		"skin":         skin,
+		"aura":         aura,
+		"species":      species,
+		"rawNameQuery": name,
+	}
+	return client.FetchData(payload)
+}
+
+// FetchPets wraps SearchPets, exiting on error.
+func FetchPets(skin, aura, species, name string) []byte {
+	data, err := SearchPets(skin, aura, species, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching data: %v\n", err)
+		os.Exit(1)
+	}
+	return data
+}
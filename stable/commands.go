@@ -0,0 +1,234 @@
+// Package stable reports on a player's pets and eggs together.
+package stable
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"bcncli/client"
+	"bcncli/common"
+	"bcncli/egg"
+	"bcncli/pet"
+	"bcncli/profile"
+	"bcncli/search"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the stable report command.
+var Cmd = &cobra.Command{
+	Use:   "stable [userId]",
+	Short: "Show a player's pets and eggs with stable capacity",
+	Long: `Show a player's pets and eggs from a single request, with a breakdown by
+category and tier.
+
+Stable capacity is --base plus the PetsStable and PetsStableExtra upgrades
+and the RaisePetSpace perk. The API does not publish the base slots every
+player starts with, so free slots are only computed when --base is given.
+
+With --value, the stable's market value is estimated from search results for
+the same species and tier, one search per owned species. This relies on
+search results carrying a "price" field, which the API does not document;
+species whose search fails or has no prices are reported and left out.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		userID := common.ParseID(args[0])
+		base, _ := cmd.Flags().GetInt("base")
+		withValue, _ := cmd.Flags().GetBool("value")
+
+		raw := client.FetchDataOrExit(map[string]interface{}{"type": "userPetsAndEggs", "id": userID})
+		if debug, _ := cmd.Flags().GetBool("debug"); debug {
+			common.PrintJSON(raw)
+			return
+		}
+		var resp struct {
+			Pets []pet.Pet `json:"pets"`
+			Eggs []egg.Egg `json:"eggs"`
+		}
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing pets and eggs data: %v\n", err)
+			os.Exit(1)
+		}
+
+		p := profile.Fetch(userID)
+		added := p.Upgrades.PetsStable + p.Upgrades.PetsStableExtra + p.Perks.RaisePetSpace
+		used := len(resp.Pets) + len(resp.Eggs)
+
+		fmt.Printf("%s (%d)\n\n", p.Name, p.ID)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Pets:\t%d\n", len(resp.Pets))
+		fmt.Fprintf(w, "Eggs:\t%d\n", len(resp.Eggs))
+		if cmd.Flags().Changed("base") {
+			capacity := base + added
+			fmt.Fprintf(w, "Capacity:\t%d of %d used, %d free (base %d + stable %d + extra %d + RaisePetSpace %d)\n",
+				used, capacity, max(capacity-used, 0), base,
+				p.Upgrades.PetsStable, p.Upgrades.PetsStableExtra, p.Perks.RaisePetSpace)
+		} else {
+			fmt.Fprintf(w, "Capacity:\t%d used; base slots unknown, pass --base for free slots (stable %d + extra %d + RaisePetSpace %d = %d added)\n",
+				used, p.Upgrades.PetsStable, p.Upgrades.PetsStableExtra, p.Perks.RaisePetSpace, added)
+		}
+		w.Flush()
+
+		printBreakdown(resp.Pets, resp.Eggs)
+
+		if withValue && len(resp.Pets) > 0 {
+			printValue(resp.Pets)
+		}
+	},
+}
+
+func init() {
+	Cmd.Flags().Bool("debug", false, "Enable debug (JSON) output")
+	Cmd.Flags().Int("base", 0, "stable slots every player has before upgrades (not published by the API; required for free slots)")
+	Cmd.Flags().Bool("value", false, "estimate market value from search results (one search per species)")
+}
+
+// tierCounts counts pets or eggs per tier.
+type tierCounts map[int]int
+
+// String renders the counts as "T1:3 T2:5".
+func (t tierCounts) String() string {
+	tiers := make([]int, 0, len(t))
+	for tier := range t {
+		tiers = append(tiers, tier)
+	}
+	sort.Ints(tiers)
+	parts := make([]string, len(tiers))
+	for i, tier := range tiers {
+		parts[i] = fmt.Sprintf("T%d:%d", tier, t[tier])
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+// printBreakdown prints pets and eggs per category with their tiers.
+func printBreakdown(pets []pet.Pet, eggs []egg.Egg) {
+	type counts struct {
+		pets, eggs int
+		tiers      tierCounts
+		eggTiers   tierCounts
+	}
+	byCategory := make(map[string]*counts)
+	get := func(species string) *counts {
+		cat := common.GetPetCategory(species)
+		if cat == "" {
+			cat = "Unknown"
+		}
+		c, ok := byCategory[cat]
+		if !ok {
+			c = &counts{tiers: tierCounts{}, eggTiers: tierCounts{}}
+			byCategory[cat] = c
+		}
+		return c
+	}
+	for _, p := range pets {
+		c := get(p.Species)
+		c.pets++
+		c.tiers[p.Tier]++
+	}
+	for _, e := range eggs {
+		c := get(e.Species)
+		c.eggs++
+		c.eggTiers[e.Tier]++
+	}
+
+	cats := make([]string, 0, len(byCategory))
+	for cat := range byCategory {
+		cats = append(cats, cat)
+	}
+	sort.Strings(cats)
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Category\tPets\tPet Tiers\tEggs\tEgg Tiers")
+	for _, cat := range cats {
+		c := byCategory[cat]
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\n", cat, c.pets, c.tiers, c.eggs, c.eggTiers)
+	}
+	w.Flush()
+}
+
+// pricedPet is a search result that may carry a price. The price field is
+// not documented; results without one are ignored.
+type pricedPet struct {
+	Species string `json:"species"`
+	Tier    int    `json:"tier"`
+	Price   int64  `json:"price"`
+}
+
+// printValue estimates the stable's value from the median price of priced
+// search results with the same species and tier.
+func printValue(pets []pet.Pet) {
+	type key struct {
+		species string
+		tier    int
+	}
+	owned := make(map[key]int)
+	for _, p := range pets {
+		owned[key{strings.ToLower(p.Species), p.Tier}]++
+	}
+	prices := make(map[key][]int64)
+	searched := make(map[string]bool)
+	for k := range owned {
+		if searched[k.species] {
+			continue
+		}
+		searched[k.species] = true
+		for _, r := range searchPriced(k.species) {
+			if r.Price > 0 {
+				rk := key{strings.ToLower(r.Species), r.Tier}
+				prices[rk] = append(prices[rk], r.Price)
+			}
+		}
+	}
+
+	var total int64
+	valued, unvalued := 0, 0
+	for k, n := range owned {
+		ps := prices[k]
+		if len(ps) == 0 {
+			unvalued += n
+			continue
+		}
+		sort.Slice(ps, func(i, j int) bool { return ps[i] < ps[j] })
+		total += ps[len(ps)/2] * int64(n)
+		valued += n
+	}
+
+	fmt.Println()
+	if valued == 0 {
+		fmt.Println("Market value: no priced search results for these pets")
+		return
+	}
+	fmt.Printf("Market value: ~%s for %d pets (median of comparable pets), %d without data\n",
+		common.FormatPrice(total), valued, unvalued)
+}
+
+// searchPriced searches every pet of a species, decoding any prices. A
+// failed search is reported on stderr and yields no results.
+func searchPriced(species string) []pricedPet {
+	raw, err := search.SearchPets("any skin", "any aura", species, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: search for %s failed: %v\n", species, err)
+		return nil
+	}
+	var list []pricedPet
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+	var resp struct {
+		Pets    []pricedPet `json:"pets"`
+		Results []pricedPet `json:"results"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not parse search results for %s: %v\n", species, err)
+		return nil
+	}
+	return append(resp.Pets, resp.Results...)
+}